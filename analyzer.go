@@ -25,11 +25,13 @@ import (
 	"os"
 	"path"
 	"reflect"
-	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 
 	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/ssa"
 )
 
 // The Context is populated with data parsed from the source code as it is scanned.
@@ -45,6 +47,8 @@ type Context struct {
 	Config   Config
 	Imports  *ImportTracker
 	Ignores  []map[string]bool
+	Tainted  map[ast.Expr][]*TraceStep // call arguments holding user input, nil without the taint analysis
+	SSA      *ssa.Package              // SSA form of the package, nil unless the SSA analysis is enabled
 }
 
 // Metrics used when reporting information about a scanning run.
@@ -53,20 +57,51 @@ type Metrics struct {
 	NumLines int `json:"lines"`
 	NumNosec int `json:"nosec"`
 	NumFound int `json:"found"`
+
+	// NosecByRule counts the #nosec annotations per suppressed rule ID
+	NosecByRule map[string]int `json:"nosec_by_rule,omitempty"`
+
+	// CacheHits and CacheMisses count the packages whose results were
+	// found, or not, in the cache
+	CacheHits   int `json:"cache_hits,omitempty"`
+	CacheMisses int `json:"cache_misses,omitempty"`
+
+	// NumBaseline counts the issues left out as they are in the baseline
+	NumBaseline int `json:"baseline,omitempty"`
 }
 
 // Analyzer object is the main object of gosec. It has methods traverse an AST
 // and invoke the correct checking rules as on each node as required.
 type Analyzer struct {
-	ignoreNosec 	bool
-	noTaintAnalysis	bool
-	ruleset     	RuleSet
-	context     	*Context
-	config      	Config
-	logger      	*log.Logger
-	issues      	[]*Issue
-	stats       	*Metrics
-	errors      	map[string][]Error // keys are file paths; values are the golang errors in those files
+	ignoreNosec     bool
+	justifyNosec    bool
+	noTaintAnalysis bool
+	concurrency     int
+	cacheDir        string
+	tests           bool
+	skipGenerated   bool
+	skipFile        func(filename string) bool
+	cache           *resultCache
+	ruleset         RuleSet
+	ssaRuleset      SSARuleSet
+	ssaPackages     map[*types.Package]*ssa.Package
+	context         *Context
+	config          Config
+	logger          *log.Logger
+	issues          []*Issue
+	stats           *Metrics
+	errors          map[string][]Error    // keys are file paths; values are the golang errors in those files
+	tainted         map[ast.Node][]*Issue // taint issues waiting for the walk to apply #nosec annotations
+	pending         map[ast.Node][]*Issue // SSA rule issues waiting for the walk to apply #nosec annotations
+	taint           *taintConfig
+	ruleSettings    map[string]*RuleSettings
+	knownRules      map[string]bool // rule IDs which #nosec annotations can list
+	summaries       map[*types.Func]*taintSummary
+	suppressions    []*Suppression
+}
+
+func newMetrics() *Metrics {
+	return &Metrics{NosecByRule: make(map[string]int)}
 }
 
 // NewAnalyzer builds a new analyzer.
@@ -75,19 +110,52 @@ func NewAnalyzer(conf Config, logger *log.Logger, noTaintAnalysis bool) *Analyze
 	if enabled, err := conf.IsGlobalEnabled(Nosec); err == nil {
 		ignoreNoSec = enabled
 	}
+	justifyNosec := false
+	if enabled, err := conf.IsGlobalEnabled(NosecJustification); err == nil {
+		justifyNosec = enabled
+	}
+	concurrency := runtime.NumCPU()
+	if value, err := conf.GetGlobal(Concurrency); err == nil {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			concurrency = n
+		}
+	}
+	cacheDir, _ := conf.GetGlobal(CacheDir)
+	tests, _ := conf.IsGlobalEnabled(Tests)
+	skipGenerated, _ := conf.IsGlobalEnabled(SkipGenerated)
 	if logger == nil {
 		logger = log.New(os.Stderr, "[gosec]", log.LstdFlags)
 	}
+	taint, err := newTaintConfig(conf)
+	if err != nil {
+		logger.Printf("Invalid %s configuration: %s", TaintAnalysisID, err)
+	}
+	ruleSettings, err := conf.GetRuleSettings()
+	if err != nil {
+		logger.Printf("Invalid %s configuration: %s", Rules, err)
+	}
 	return &Analyzer{
-		ignoreNosec: 		ignoreNoSec,
-		noTaintAnalysis:	noTaintAnalysis,
-		ruleset:     		make(RuleSet),
-		context:     		&Context{},
-		config:		      	conf,
-		logger:      		logger,
-		issues:      		make([]*Issue, 0, 16),
-		stats:       		&Metrics{},
-		errors:      		make(map[string][]Error),
+		ignoreNosec:     ignoreNoSec,
+		justifyNosec:    justifyNosec,
+		noTaintAnalysis: noTaintAnalysis,
+		concurrency:     concurrency,
+		cacheDir:        cacheDir,
+		tests:           tests,
+		skipGenerated:   skipGenerated,
+		taint:           taint,
+		ruleSettings:    ruleSettings,
+		knownRules:      make(map[string]bool),
+		ruleset:         make(RuleSet),
+		ssaRuleset:      make(SSARuleSet),
+		context:         &Context{},
+		config:          conf,
+		logger:          logger,
+		issues:          make([]*Issue, 0, 16),
+		stats:           newMetrics(),
+		errors:          make(map[string][]Error),
+		tainted:         make(map[ast.Node][]*Issue),
+		pending:         make(map[ast.Node][]*Issue),
+		suppressions:    make([]*Suppression, 0),
 	}
 }
 
@@ -95,20 +163,73 @@ func NewAnalyzer(conf Config, logger *log.Logger, noTaintAnalysis bool) *Analyze
 // packages
 func (gosec *Analyzer) LoadRules(ruleDefinitions map[string]RuleBuilder) {
 	for id, def := range ruleDefinitions {
+		gosec.knownRules[id] = true
+		if settings, ok := gosec.ruleSettings[id]; ok && settings.Enabled != nil && !*settings.Enabled {
+			continue
+		}
 		r, nodes := def(id, gosec.config)
 		gosec.ruleset.Register(r, nodes...)
+		if ssaRule, ok := r.(SSARule); ok {
+			gosec.ssaRuleset.Register(ssaRule, ssaRule.Instructions()...)
+		}
+	}
+}
+
+// AddRuleIDs declares the IDs of rules which are not loaded, e.g. the
+// excluded rules, so that the #nosec annotations can still list them
+func (gosec *Analyzer) AddRuleIDs(ids ...string) {
+	for _, id := range ids {
+		gosec.knownRules[id] = true
+	}
+}
+
+// isRule checks whether an ID names a rule, a taint analysis sink or a
+// rule which has a legacy alias
+func (gosec *Analyzer) isRule(id string) bool {
+	if gosec.knownRules[id] || id == TaintAnalysisID || id == NosecJustificationID {
+		return true
+	}
+	for _, rule := range ruleAliases {
+		if rule == id {
+			return true
+		}
+	}
+	if gosec.taint != nil {
+		for _, sink := range gosec.taint.sinks {
+			if sink.RuleID == id {
+				return true
+			}
+		}
 	}
+	return false
+}
+
+// scanPackage is a package created from the files of a scanned directory,
+// with its cache key and its results once checked
+type scanPackage struct {
+	name   string
+	dir    string
+	files  []string
+	key    string
+	result *Analyzer
 }
 
 // Process kicks off the analysis process for a given package
 func (gosec *Analyzer) Process(buildTags []string, packagePaths ...string) error {
-	ctx := build.Default
-	ctx.BuildTags = append(ctx.BuildTags, buildTags...)
-	packageConfig := loader.Config{
-		Build:       &ctx,
-		ParserMode:  parser.ParseComments,
-		AllowErrors: true,
+	gosec.cache = nil
+	if gosec.cacheDir != "" {
+		cache, err := newResultCache(gosec.cacheDir, gosec)
+		if err != nil {
+			gosec.logger.Printf("Unable to use the cache directory %s: %v", gosec.cacheDir, err)
+		}
+		gosec.cache = cache
 	}
+
+	// The packages of each Go module are loaded separately, since the
+	// imports of a module are resolved from its root directory. Packages
+	// outside of any module are loaded from the $GOPATH.
+	var roots []string
+	packages := make(map[string][]*scanPackage)
 	for _, packagePath := range packagePaths {
 		abspath, err := GetPkgAbsPath(packagePath)
 		if err != nil {
@@ -122,94 +243,362 @@ func (gosec *Analyzer) Process(buildTags []string, packagePaths ...string) error
 			return err
 		}
 
-		var packageFiles []string
-		for _, filename := range basePackage.GoFiles {
-			packageFiles = append(packageFiles, path.Join(packagePath, filename))
+		packageFiles := gosec.filterFiles(packagePath, basePackage.GoFiles)
+		var testFiles []string
+		if gosec.tests {
+			packageFiles = append(packageFiles, gosec.filterFiles(packagePath, basePackage.TestGoFiles)...)
+			testFiles = gosec.filterFiles(packagePath, basePackage.XTestGoFiles)
+		}
+		if len(packageFiles) == 0 && len(testFiles) == 0 {
+			continue
+		}
+
+		root, _ := GetModuleRoot(abspath)
+		if _, ok := packages[root]; !ok {
+			roots = append(roots, root)
+		}
+		if len(packageFiles) > 0 {
+			packages[root] = append(packages[root], &scanPackage{name: basePackage.Name, dir: abspath, files: packageFiles})
 		}
+		// the external test package is type checked on its own
+		if len(testFiles) > 0 {
+			packages[root] = append(packages[root], &scanPackage{name: basePackage.Name + "_test", dir: abspath, files: testFiles})
+		}
+	}
 
-		packageConfig.CreateFromFilenames(basePackage.Name, packageFiles...)
+	for _, root := range roots {
+		if err := gosec.load(root, buildTags, packages[root]); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	builtPackage, err := packageConfig.Load()
-	if err != nil {
-		return err
-	}
-	for _, packageInfo := range builtPackage.AllPackages {
-		if len(packageInfo.Errors) != 0 {
-			for _, packErr := range packageInfo.Errors {
-				// infoErr contains information about the error
-				// at index 0 is the file path
-				// at index 1 is the line; index 2 is for column
-				// at index 3 is the actual error
-				infoErr := strings.Split(packErr.Error(), ":")
-				filePath := infoErr[0]
-				line, err := strconv.Atoi(infoErr[1])
-				if err != nil {
-					return err
-				}
-				column, err := strconv.Atoi(infoErr[2])
-				if err != nil {
-					return err
-				}
-				newErr := NewError(line, column, strings.TrimSpace(infoErr[3]))
+// load checks the packages of a module, or of the $GOPATH if the root is
+// empty. The packages whose results are cached are not loaded.
+func (gosec *Analyzer) load(root string, buildTags []string, packages []*scanPackage) error {
+	packageConfig := newLoaderConfig(root, buildTags)
+	gosec.lookup(packageConfig.Build, packages)
+	var unchecked []*scanPackage
+	for _, pkg := range packages {
+		if pkg.result == nil {
+			packageConfig.CreateFromFilenames(pkg.name, pkg.files...)
+			unchecked = append(unchecked, pkg)
+		}
+	}
+	if len(unchecked) > 0 {
+		if root != "" {
+			gosec.logger.Println("Loading module:", root)
+		}
+		builtPackage, err := packageConfig.Load()
+		if err != nil {
+			return err
+		}
+		if err := gosec.check(builtPackage, packages, unchecked); err != nil {
+			return err
+		}
+	}
+	for _, pkg := range packages {
+		gosec.merge(pkg.result)
+	}
+	sortErrors(gosec.errors) // sorts errors by line and column in the file
+	return nil
+}
 
-				if errSlice, ok := gosec.errors[filePath]; ok {
-					gosec.errors[filePath] = append(errSlice, *newErr)
-				} else {
-					errSlice = make([]Error, 0)
-					gosec.errors[filePath] = append(errSlice, *newErr)
-				}
+// SkipFiles sets the function telling which files of the scanned packages
+// are left out
+func (gosec *Analyzer) SkipFiles(skip func(filename string) bool) {
+	gosec.skipFile = skip
+}
+
+// filterFiles returns the paths of the files of a package which are not
+// skipped
+func (gosec *Analyzer) filterFiles(packagePath string, filenames []string) []string {
+	var files []string
+	for _, filename := range filenames {
+		file := path.Join(packagePath, filename)
+		if gosec.skipFile != nil && gosec.skipFile(file) {
+			continue
+		}
+		if gosec.skipGenerated {
+			if generated, err := IsGeneratedFile(file); err == nil && generated {
+				gosec.logger.Println("Skipping generated file:", file)
+				continue
 			}
 		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// newLoaderConfig creates the configuration used to load the packages of
+// the module rooted at the given directory, or of the $GOPATH if the root
+// is empty. Within a module the go command resolves the imports, which
+// takes the go.mod requirements, replace directives and the module cache
+// into account.
+func newLoaderConfig(moduleRoot string, buildTags []string) *loader.Config {
+	ctx := build.Default
+	ctx.BuildTags = append(ctx.BuildTags, buildTags...)
+	ctx.Dir = moduleRoot
+	return &loader.Config{
+		Build:       &ctx,
+		Cwd:         moduleRoot,
+		ParserMode:  parser.ParseComments,
+		AllowErrors: true,
+	}
+}
+
+// packageErrors collects the golang errors of a loaded package and of the
+// packages it imports
+func packageErrors(program *loader.Program, pkg *loader.PackageInfo) (map[string][]Error, error) {
+	errors := make(map[string][]Error)
+	seen := make(map[*types.Package]bool)
+	infos := []*loader.PackageInfo{pkg}
+	for len(infos) > 0 {
+		info := infos[0]
+		infos = infos[1:]
+		if seen[info.Pkg] {
+			continue
+		}
+		seen[info.Pkg] = true
+		for _, packErr := range info.Errors {
+			// infoErr contains information about the error
+			// at index 0 is the file path
+			// at index 1 is the line; index 2 is for column
+			// at index 3 is the actual error
+			infoErr := strings.SplitN(packErr.Error(), ":", 4)
+			filePath := infoErr[0]
+			line, err := strconv.Atoi(infoErr[1])
+			if err != nil {
+				return nil, err
+			}
+			column, err := strconv.Atoi(infoErr[2])
+			if err != nil {
+				return nil, err
+			}
+			newErr := NewError(line, column, strings.TrimSpace(infoErr[3]))
+			errors[filePath] = append(errors[filePath], *newErr)
+		}
+		for _, imported := range info.Pkg.Imports() {
+			if importedInfo := program.AllPackages[imported]; importedInfo != nil {
+				infos = append(infos, importedInfo)
+			}
+		}
+	}
+	return errors, nil
+}
+
+// check runs the rules over the packages created from the scanned
+// directories which were not found in the cache. All the scanned packages
+// are given, as the taint summaries follow the calls into the cached
+// packages, which are loaded as imports.
+func (gosec *Analyzer) check(builtPackage *loader.Program, packages []*scanPackage, unchecked []*scanPackage) error {
+	errors := make([]map[string][]Error, len(unchecked))
+	for i, pkg := range builtPackage.Created {
+		pkgErrors, err := packageErrors(builtPackage, pkg)
+		if err != nil {
+			return err
+		}
+		errors[i] = pkgErrors
+	}
+
+	if !gosec.noTaintAnalysis {
+		dirs := make(map[string]bool)
+		for _, pkg := range packages {
+			dirs[pkg.dir] = true
+		}
+		gosec.summaries = summarizeTaint(builtPackage, dirs, gosec.taint)
+	}
+	gosec.ssaPackages = nil
+	if enabled, err := gosec.config.IsGlobalEnabled(SSA); err == nil && enabled && len(gosec.ssaRuleset) > 0 {
+		gosec.ssaPackages = buildSSA(builtPackage, gosec.logger)
 	}
-	sortErrors(gosec.errors) // sorts errors by line and column in the file
 
-	for _, pkg := range builtPackage.Created {
-		gosec.logger.Println("Checking package:", pkg.String())
-		for _, file := range pkg.Files {
-			gosec.logger.Println("Checking file:", builtPackage.Fset.File(file.Pos()).Name())
-			gosec.context.FileSet = builtPackage.Fset
-			gosec.context.Config = gosec.config
-			gosec.context.Comments = ast.NewCommentMap(gosec.context.FileSet, file, file.Comments)
-			gosec.context.Root = file
-			gosec.context.Info = &pkg.Info
-			gosec.context.Pkg = pkg.Pkg
-			gosec.context.PkgFiles = pkg.Files
-			gosec.context.Imports = NewImportTracker()
-			gosec.context.Imports.TrackPackages(gosec.context.Pkg.Imports()...)
-			
-			//Integrate your code here
-			if gosec.noTaintAnalysis == false {
-	   			TaintAnalysis(gosec)		
+	// Each package is checked by a worker with its own context. The results
+	// are merged in the order of the packages to keep the report stable.
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < gosec.concurrency && w < len(unchecked); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				unchecked[i].result = gosec.checkPackage(builtPackage.Fset, builtPackage.Created[i])
+				unchecked[i].result.errors = errors[i]
 			}
-			ast.Walk(gosec, file)
-			gosec.stats.NumFiles++
-			gosec.stats.NumLines += builtPackage.Fset.File(file.Pos()).LineCount()
+		}()
+	}
+	for i := range unchecked {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, pkg := range unchecked {
+		if pkg.key == "" {
+			continue
+		}
+		result := pkg.result
+		entry := &cacheEntry{Issues: result.issues, Metrics: result.stats, Suppressions: result.suppressions, Errors: result.errors}
+		if err := gosec.cache.store(pkg.key, entry); err != nil {
+			gosec.logger.Printf("Unable to cache the results of %s: %v", builtPackage.Created[i].String(), err)
 		}
 	}
 	return nil
 }
 
+// CheckTypedPackage runs the rules over a package loaded and type checked
+// by another driver, e.g. a go/analysis pass, and adds the results to this
+// analyzer. The taint summaries of the imported functions are given by the
+// imported function, which returns nil for the functions without summary.
+// It returns the taint summaries of the functions declared by the package
+// through which user input flows, to be given for the importing packages.
+func (gosec *Analyzer) CheckTypedPackage(fset *token.FileSet, files []*ast.File, pkg *types.Package, info *types.Info, ssaPkg *ssa.Package, imported func(*types.Func) *TaintFact) map[*types.Func]*TaintFact {
+	facts := make(map[*types.Func]*TaintFact)
+	gosec.summaries = nil
+	if !gosec.noTaintAnalysis {
+		known := make(map[*types.Func]*taintSummary)
+		for _, obj := range info.Uses {
+			if fn, ok := obj.(*types.Func); ok && fn.Pkg() != nil && fn.Pkg() != pkg {
+				if _, ok := known[fn]; !ok {
+					if fact := imported(fn); fact != nil {
+						known[fn] = gosec.taint.summary(fact)
+					}
+				}
+			}
+		}
+		funcs := make(map[*types.Func]*summaryFunc)
+		for _, file := range files {
+			for _, decl := range file.Decls {
+				if fnDecl, ok := decl.(*ast.FuncDecl); ok && fnDecl.Body != nil {
+					if fn, ok := info.Defs[fnDecl.Name].(*types.Func); ok {
+						funcs[fn] = &summaryFunc{info: info, decl: fnDecl}
+					}
+				}
+			}
+		}
+		gosec.summaries = summarizeFuncs(funcs, gosec.taint, known)
+		for fn := range funcs {
+			if fact := gosec.taint.fact(gosec.summaries[fn]); fact.flows() {
+				facts[fn] = fact
+			}
+		}
+	}
+	gosec.ssaPackages = nil
+	if enabled, err := gosec.config.IsGlobalEnabled(SSA); err == nil && enabled && len(gosec.ssaRuleset) > 0 && ssaPkg != nil {
+		gosec.ssaPackages = map[*types.Package]*ssa.Package{pkg: ssaPkg}
+	}
+	gosec.merge(gosec.checkPackage(fset, &loader.PackageInfo{Pkg: pkg, Files: files, Info: *info}))
+	return facts
+}
+
+// fork creates an analyzer which shares the rules and settings of this
+// analyzer, but has its own context and results
+func (gosec *Analyzer) fork() *Analyzer {
+	return &Analyzer{
+		ignoreNosec:     gosec.ignoreNosec,
+		justifyNosec:    gosec.justifyNosec,
+		noTaintAnalysis: gosec.noTaintAnalysis,
+		concurrency:     1,
+		ruleset:         gosec.ruleset,
+		ssaRuleset:      gosec.ssaRuleset,
+		ssaPackages:     gosec.ssaPackages,
+		context:         &Context{},
+		config:          gosec.config,
+		logger:          gosec.logger,
+		issues:          make([]*Issue, 0, 16),
+		stats:           newMetrics(),
+		errors:          make(map[string][]Error),
+		tainted:         make(map[ast.Node][]*Issue),
+		pending:         make(map[ast.Node][]*Issue),
+		taint:           gosec.taint,
+		ruleSettings:    gosec.ruleSettings,
+		knownRules:      gosec.knownRules,
+		summaries:       gosec.summaries,
+		suppressions:    make([]*Suppression, 0),
+	}
+}
+
+// checkPackage runs the rules over the files of a package and returns
+// the analyzer holding the results
+func (gosec *Analyzer) checkPackage(fset *token.FileSet, pkg *loader.PackageInfo) *Analyzer {
+	worker := gosec.fork()
+	worker.logger.Println("Checking package:", pkg.String())
+	if ssaPkg, ok := worker.ssaPackages[pkg.Pkg]; ok {
+		worker.context = &Context{
+			FileSet:  fset,
+			Config:   worker.config,
+			Info:     &pkg.Info,
+			Pkg:      pkg.Pkg,
+			PkgFiles: pkg.Files,
+			SSA:      ssaPkg,
+		}
+		worker.checkSSA()
+	}
+	for _, file := range pkg.Files {
+		worker.logger.Println("Checking file:", fset.File(file.Pos()).Name())
+		worker.context.FileSet = fset
+		worker.context.Config = worker.config
+		worker.context.Comments = ast.NewCommentMap(worker.context.FileSet, file, file.Comments)
+		worker.context.Root = file
+		worker.context.Info = &pkg.Info
+		worker.context.Pkg = pkg.Pkg
+		worker.context.PkgFiles = pkg.Files
+		worker.context.Imports = NewImportTracker()
+		worker.context.Imports.TrackPackages(worker.context.Pkg.Imports()...)
+
+		//Integrate your code here
+		if worker.noTaintAnalysis == false {
+			TaintAnalysis(worker)
+		}
+		ast.Walk(worker, file)
+		worker.stats.NumFiles++
+		worker.stats.NumLines += fset.File(file.Pos()).LineCount()
+	}
+	return worker
+}
+
+// merge adds the results of a worker to the results of this analyzer
+func (gosec *Analyzer) merge(worker *Analyzer) {
+	gosec.issues = append(gosec.issues, worker.issues...)
+	gosec.suppressions = append(gosec.suppressions, worker.suppressions...)
+	gosec.stats.NumFiles += worker.stats.NumFiles
+	gosec.stats.NumLines += worker.stats.NumLines
+	gosec.stats.NumNosec += worker.stats.NumNosec
+	gosec.stats.NumFound += worker.stats.NumFound
+	for id, count := range worker.stats.NosecByRule {
+		gosec.stats.NosecByRule[id] += count
+	}
+	// the errors of the packages imported by several scanned packages are
+	// reported once
+	for file, errors := range worker.errors {
+	ERRORS:
+		for _, e := range errors {
+			for _, known := range gosec.errors[file] {
+				if known == e {
+					continue ERRORS
+				}
+			}
+			gosec.errors[file] = append(gosec.errors[file], e)
+		}
+	}
+}
+
 // ignore a node (and sub-tree) if it is tagged with a "#nosec" comment
 func (gosec *Analyzer) ignore(n ast.Node) ([]string, bool) {
 	if groups, ok := gosec.context.Comments[n]; ok && !gosec.ignoreNosec {
 		for _, group := range groups {
-			if strings.Contains(group.Text(), "#nosec") {
+			if ignores, justification, found := parseNosec(group.Text(), gosec.isRule); found {
 				gosec.stats.NumNosec++
-
-				// Pull out the specific rules that are listed to be ignored.
-				re := regexp.MustCompile("(G\\d{3})")
-				matches := re.FindAllStringSubmatch(group.Text(), -1)
+				gosec.suppress(n, ignores, justification)
 
 				// If no specific rules were given, ignore everything.
-				if matches == nil || len(matches) == 0 {
+				if len(ignores) == 0 {
+					gosec.stats.NosecByRule[NosecAll]++
 					return nil, true
 				}
-
-				// Find the rule IDs to ignore.
-				var ignores []string
-				for _, v := range matches {
-					ignores = append(ignores, v[1])
+				for _, id := range ignores {
+					gosec.stats.NosecByRule[id]++
 				}
 				return ignores, false
 			}
@@ -218,6 +607,22 @@ func (gosec *Analyzer) ignore(n ast.Node) ([]string, bool) {
 	return nil, false
 }
 
+// suppress records a #nosec annotation for the audit trail and reports it
+// when a justification is required but missing
+func (gosec *Analyzer) suppress(n ast.Node, ruleIDs []string, justification string) {
+	fobj := gosec.context.FileSet.File(n.Pos())
+	gosec.suppressions = append(gosec.suppressions, &Suppression{
+		File:          fobj.Name(),
+		Line:          lineRange(fobj, n),
+		RuleIDs:       ruleIDs,
+		Justification: justification,
+	})
+	if gosec.justifyNosec && justification == "" {
+		issue := NewIssue(gosec.context, n, NosecJustificationID, "#nosec annotation without a justification", Low, High)
+		gosec.addIssue(issue)
+	}
+}
+
 // Visit runs the gosec visitor logic over an AST created by parsing go code.
 // Rule methods added with AddRule will be invoked as necessary.
 func (gosec *Analyzer) Visit(n ast.Node) ast.Visitor {
@@ -253,6 +658,7 @@ func (gosec *Analyzer) Visit(n ast.Node) ast.Visitor {
 	// Track aliased and initialization imports
 	gosec.context.Imports.TrackImport(n)
 
+	traced := false
 	for _, rule := range gosec.ruleset.RegisteredFor(n) {
 		if _, ok := ignores[rule.ID()]; ok {
 			continue
@@ -264,21 +670,67 @@ func (gosec *Analyzer) Visit(n ast.Node) ast.Visitor {
 			gosec.logger.Printf("Rule error: %v => %s (%s:%d)\n", reflect.TypeOf(rule), err, file, line)
 		}
 		if issue != nil {
-			gosec.issues = append(gosec.issues, issue)
-			gosec.stats.NumFound++
+			gosec.addIssue(issue)
+			traced = traced || len(issue.Trace) > 0
+		}
+	}
+
+	// Report the taint issues found on this node unless suppressed, or
+	// already reported with their flow by a rule
+	if _, ok := ignores[TaintAnalysisID]; !ok && !traced {
+		for _, issue := range gosec.tainted[n] {
+			if _, ok := ignores[issue.RuleID]; ok {
+				continue
+			}
+			gosec.addIssue(issue)
 		}
 	}
+	delete(gosec.tainted, n)
+
+	// Report the issues of the SSA rules found on this node unless suppressed
+	for _, issue := range gosec.pending[n] {
+		if _, ok := ignores[issue.RuleID]; ok {
+			continue
+		}
+		gosec.addIssue(issue)
+	}
+	delete(gosec.pending, n)
 	return gosec
 }
 
-// Report returns the current issues discovered and the metrics about the scan
-func (gosec *Analyzer) Report() ([]*Issue, *Metrics, map[string][]Error) {
-	return gosec.issues, gosec.stats, gosec.errors
+// addIssue records an issue found while checking, with the settings of
+// its rule applied
+func (gosec *Analyzer) addIssue(issue *Issue) {
+	if settings, ok := gosec.ruleSettings[issue.RuleID]; ok {
+		if settings.Enabled != nil && !*settings.Enabled {
+			return
+		}
+		if settings.Severity != nil {
+			issue.Severity = *settings.Severity
+		}
+		if settings.Confidence != nil {
+			issue.Confidence = *settings.Confidence
+		}
+		if settings.Message != "" {
+			issue.What = settings.Message
+		}
+	}
+	gosec.issues = append(gosec.issues, issue)
+	gosec.stats.NumFound++
+}
+
+// Report returns the current issues discovered, the metrics about the scan,
+// the golang errors and the #nosec suppressions encountered
+func (gosec *Analyzer) Report() ([]*Issue, *Metrics, map[string][]Error, []*Suppression) {
+	return gosec.issues, gosec.stats, gosec.errors, gosec.suppressions
 }
 
 // Reset clears state such as context, issues and metrics from the configured analyzer
 func (gosec *Analyzer) Reset() {
 	gosec.context = &Context{}
 	gosec.issues = make([]*Issue, 0, 16)
-	gosec.stats = &Metrics{}
+	gosec.stats = newMetrics()
+	gosec.tainted = make(map[ast.Node][]*Issue)
+	gosec.pending = make(map[ast.Node][]*Issue)
+	gosec.suppressions = make([]*Suppression, 0)
 }
@@ -1,9 +1,11 @@
 package gosec_test
 
 import (
+	"bytes"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/securego/gosec"
@@ -23,7 +25,7 @@ var _ = Describe("Analyzer", func() {
 	)
 	BeforeEach(func() {
 		logger, _ = testutils.NewLogger()
-		analyzer = gosec.NewAnalyzer(nil, logger)
+		analyzer = gosec.NewAnalyzer(nil, logger, false)
 	})
 
 	Context("when processing a package", func() {
@@ -68,7 +70,7 @@ var _ = Describe("Analyzer", func() {
 			pkg.Build()
 			err := analyzer.Process(buildTags, pkg.Path)
 			Expect(err).ShouldNot(HaveOccurred())
-			_, metrics, _ := analyzer.Report()
+			_, metrics, _, _ := analyzer.Report()
 			Expect(metrics.NumFiles).To(Equal(2))
 		})
 
@@ -90,14 +92,249 @@ var _ = Describe("Analyzer", func() {
 			pkg2.Build()
 			err := analyzer.Process(buildTags, pkg1.Path, pkg2.Path)
 			Expect(err).ShouldNot(HaveOccurred())
-			_, metrics, _ := analyzer.Report()
+			_, metrics, _, _ := analyzer.Report()
 			Expect(metrics.NumFiles).To(Equal(2))
 		})
 
+		It("should report the issues of packages checked in parallel in the order of the packages", func() {
+			concurrentConfig := gosec.NewConfig()
+			concurrentConfig.SetGlobal(gosec.Concurrency, "4")
+			customAnalyzer := gosec.NewAnalyzer(concurrentConfig, logger, false)
+			customAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+
+			source := testutils.SampleCodeInsecureLib[0].Code[0]
+			var paths []string
+			for i := 0; i < 6; i++ {
+				pkg := testutils.NewTestPackage()
+				defer pkg.Close()
+				pkg.AddFile("md5.go", source)
+				pkg.Build()
+				paths = append(paths, pkg.Path)
+			}
+
+			err := customAnalyzer.Process(buildTags, paths...)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, _, _ := customAnalyzer.Report()
+			Expect(metrics.NumFiles).To(Equal(len(paths)))
+			Expect(issues).Should(HaveLen(len(paths)))
+			for i, issue := range issues {
+				Expect(filepath.Dir(issue.File)).Should(Equal(paths[i]))
+			}
+		})
+
+		It("should report the same results with concurrent workers as with a sequential run", func() {
+			// the first samples of a few rules and of the taint analysis
+			// keep the workers busy, without checking every sample twice
+			var paths []string
+			for _, samples := range [][]testutils.CodeSample{testutils.SampleCodeHardcreds, testutils.SampleCodeTaintHttp, testutils.SampleCodeCmdExec, testutils.SampleCodeErrorCheck, testutils.SampleCodeInsecureLib} {
+				pkg := testutils.NewTestPackage()
+				defer pkg.Close()
+				pkg.AddFile("main.go", samples[0].Code[0])
+				pkg.Write()
+				paths = append(paths, pkg.Path)
+			}
+
+			run := func(concurrency string) ([]*gosec.Issue, *gosec.Metrics, map[string][]gosec.Error) {
+				config := gosec.NewConfig()
+				config.SetGlobal(gosec.Concurrency, concurrency)
+				customAnalyzer := gosec.NewAnalyzer(config, logger, false)
+				customAnalyzer.LoadRules(rules.Generate().Builders())
+				err := customAnalyzer.Process(buildTags, paths...)
+				Expect(err).ShouldNot(HaveOccurred())
+				issues, metrics, errors, _ := customAnalyzer.Report()
+				return issues, metrics, errors
+			}
+			issues, metrics, errors := run("1")
+			concurrentIssues, concurrentMetrics, concurrentErrors := run("4")
+			Expect(issues).ShouldNot(BeEmpty())
+			Expect(concurrentIssues).Should(Equal(issues))
+			Expect(concurrentMetrics).Should(Equal(metrics))
+			Expect(concurrentErrors).Should(Equal(errors))
+		})
+
+		It("should build the SSA form of the other packages when the SSA builder fails on a package", func() {
+			ssaConfig := gosec.NewConfig()
+			ssaConfig.SetGlobal(gosec.SSA, "enabled")
+			ssaAnalyzer := gosec.NewAnalyzer(ssaConfig, logger, false)
+			ssaAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "bad-tls")).Builders())
+
+			// the insecure settings of the first samples are not held in
+			// variables, so they are still found on the AST
+			var paths []string
+			for _, sample := range []testutils.CodeSample{testutils.SampleCodeBadTls[0], testutils.SampleCodeBadTls[1], testutils.SampleCodeBadTlsSSA[0]} {
+				pkg := testutils.NewTestPackage()
+				defer pkg.Close()
+				pkg.AddFile("main.go", sample.Code[0])
+				pkg.Build()
+				paths = append(paths, pkg.Path)
+			}
+
+			err := ssaAnalyzer.Process(buildTags, paths...)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, _, _ := ssaAnalyzer.Report()
+			Expect(issues).Should(HaveLen(3))
+			Expect(filepath.Dir(issues[2].File)).Should(Equal(paths[2]))
+		})
+
+		It("should reuse the cached results of the unchanged packages", func() {
+			cacheDir, err := ioutil.TempDir("", "gosec_cache")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(cacheDir)
+			cacheConfig := gosec.NewConfig()
+			cacheConfig.SetGlobal(gosec.CacheDir, cacheDir)
+			var logOutput *bytes.Buffer
+			scan := func(paths ...string) ([]*gosec.Issue, *gosec.Metrics) {
+				var cachedLogger *log.Logger
+				cachedLogger, logOutput = testutils.NewLogger()
+				cachedAnalyzer := gosec.NewAnalyzer(cacheConfig, cachedLogger, false)
+				cachedAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+				err := cachedAnalyzer.Process(buildTags, paths...)
+				Expect(err).ShouldNot(HaveOccurred())
+				issues, metrics, _, _ := cachedAnalyzer.Report()
+				return issues, metrics
+			}
+
+			source := testutils.SampleCodeInsecureLib[0].Code[0]
+			var paths []string
+			for i := 0; i < 2; i++ {
+				pkg := testutils.NewTestPackage()
+				defer pkg.Close()
+				pkg.AddFile("md5.go", source)
+				pkg.Build()
+				paths = append(paths, pkg.Path)
+			}
+
+			issues, metrics := scan(paths...)
+			Expect(issues).Should(HaveLen(2))
+			Expect(metrics.CacheHits).Should(Equal(0))
+			Expect(metrics.CacheMisses).Should(Equal(2))
+
+			cachedIssues, cachedMetrics := scan(paths...)
+			Expect(cachedIssues).Should(Equal(issues))
+			Expect(cachedMetrics.NumFiles).Should(Equal(2))
+			Expect(cachedMetrics.CacheHits).Should(Equal(2))
+			Expect(cachedMetrics.CacheMisses).Should(Equal(0))
+			Expect(logOutput.String()).ShouldNot(ContainSubstring("Checking package:"))
+
+			fixed := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec", 1)
+			err = ioutil.WriteFile(filepath.Join(paths[1], "md5.go"), []byte(fixed), 0644)
+			Expect(err).ShouldNot(HaveOccurred())
+			changedIssues, changedMetrics := scan(paths...)
+			Expect(changedIssues).Should(HaveLen(1))
+			Expect(changedMetrics.NumNosec).Should(Equal(1))
+			Expect(changedMetrics.CacheHits).Should(Equal(1))
+			Expect(changedMetrics.CacheMisses).Should(Equal(1))
+		})
+
+		It("should report the cached golang errors of a package", func() {
+			cacheDir, err := ioutil.TempDir("", "gosec_cache")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(cacheDir)
+			cacheConfig := gosec.NewConfig()
+			cacheConfig.SetGlobal(gosec.CacheDir, cacheDir)
+			scan := func(path string) (map[string][]gosec.Error, *gosec.Metrics) {
+				cachedAnalyzer := gosec.NewAnalyzer(cacheConfig, logger, false)
+				cachedAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+				err := cachedAnalyzer.Process(buildTags, path)
+				Expect(err).ShouldNot(HaveOccurred())
+				_, metrics, errors, _ := cachedAnalyzer.Report()
+				return errors, metrics
+			}
+
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("main.go", `
+package main
+
+func main() {
+	undefined()
+}`)
+			pkg.Build()
+			errors, metrics := scan(pkg.Path)
+			Expect(errors).ShouldNot(BeEmpty())
+			Expect(metrics.CacheMisses).Should(Equal(1))
+
+			cachedErrors, cachedMetrics := scan(pkg.Path)
+			Expect(cachedMetrics.CacheHits).Should(Equal(1))
+			Expect(cachedErrors).Should(Equal(errors))
+		})
+
+		It("should skip the files left out by the filter, the generated files and the test files", func() {
+			skipConfig := gosec.NewConfig()
+			skipConfig.SetGlobal(gosec.SkipGenerated, "enabled")
+			skipAnalyzer := gosec.NewAnalyzer(skipConfig, logger, false)
+			skipAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+			skipAnalyzer.SkipFiles(func(filename string) bool {
+				return strings.HasSuffix(filename, "skipped.go")
+			})
+
+			source := testutils.SampleCodeInsecureLib[0].Code[0]
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("md5.go", source)
+			pkg.AddFile("skipped.go", strings.Replace(source, "func main()", "func skipped()", 1))
+			pkg.AddFile("generated.go", "// Code generated by a tool. DO NOT EDIT.\n"+strings.Replace(source, "func main()", "func generated()", 1))
+			pkg.AddFile("md5_test.go", strings.Replace(source, "func main()", "func tested()", 1))
+			pkg.Build()
+
+			err := skipAnalyzer.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, _, _ := skipAnalyzer.Report()
+			Expect(metrics.NumFiles).Should(Equal(1))
+			Expect(issues).Should(HaveLen(1))
+			Expect(issues[0].File).Should(HaveSuffix("md5.go"))
+
+			skipConfig.SetGlobal(gosec.Tests, "enabled")
+			testAnalyzer := gosec.NewAnalyzer(skipConfig, logger, false)
+			testAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+			err = testAnalyzer.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, metrics, _, _ = testAnalyzer.Report()
+			Expect(metrics.NumFiles).Should(Equal(3))
+		})
+
+		It("should be able to analyze packages of a Go module outside of the $GOPATH", func() {
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+			dir, err := ioutil.TempDir("", "gosec_module")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			Expect(os.Mkdir(filepath.Join(dir, "hash"), 0755)).Should(Succeed())
+			files := map[string]string{
+				"go.mod": "module example.com/app\n",
+				"main.go": `
+				package main
+				import (
+					"fmt"
+					"example.com/app/hash"
+				)
+				func main(){
+					fmt.Println(hash.Sum("gosec"))
+				}`,
+				filepath.Join("hash", "hash.go"): `
+				package hash
+				import "crypto/md5"
+				func Sum(s string) []byte {
+					h := md5.New()
+					h.Write([]byte(s))
+					return h.Sum(nil)
+				}`,
+			}
+			for name, content := range files {
+				Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).Should(Succeed())
+			}
+
+			err = analyzer.Process(buildTags, dir, filepath.Join(dir, "hash"))
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, golangErrors, _ := analyzer.Report()
+			Expect(golangErrors).Should(BeEmpty())
+			Expect(metrics.NumFiles).To(Equal(2))
+			Expect(issues).Should(HaveLen(1))
+		})
+
 		It("should find errors when nosec is not in use", func() {
 
 			// Rule for MD5 weak crypto usage
-			sample := testutils.SampleCodeinsecure-lib[0]
+			sample := testutils.SampleCodeInsecureLib[0]
 			source := sample.Code[0]
 			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
 
@@ -106,7 +343,7 @@ var _ = Describe("Analyzer", func() {
 			controlPackage.AddFile("md5.go", source)
 			controlPackage.Build()
 			analyzer.Process(buildTags, controlPackage.Path)
-			controlIssues, _, _ := analyzer.Report()
+			controlIssues, _, _, _ := analyzer.Report()
 			Expect(controlIssues).Should(HaveLen(sample.Errors))
 
 		})
@@ -122,7 +359,7 @@ var _ = Describe("Analyzer", func() {
 			pkg.Build()
 			err := analyzer.Process(buildTags, pkg.Path)
 			Expect(err).ShouldNot(HaveOccurred())
-			_, _, golangErrors := analyzer.Report()
+			_, _, golangErrors, _ := analyzer.Report()
 			keys := make([]string, len(golangErrors))
 			i := 0
 			for key := range golangErrors {
@@ -138,7 +375,7 @@ var _ = Describe("Analyzer", func() {
 
 		It("should not report errors when a nosec comment is present", func() {
 			// Rule for MD5 weak crypto usage
-			sample := testutils.SampleCodeinsecure-lib[0]
+			sample := testutils.SampleCodeInsecureLib[0]
 			source := sample.Code[0]
 			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
 
@@ -149,13 +386,13 @@ var _ = Describe("Analyzer", func() {
 			nosecPackage.Build()
 
 			analyzer.Process(buildTags, nosecPackage.Path)
-			nosecIssues, _, _ := analyzer.Report()
+			nosecIssues, _, _, _ := analyzer.Report()
 			Expect(nosecIssues).Should(BeEmpty())
 		})
 
 		It("should not report errors when an exclude comment is present for the correct rule", func() {
 			// Rule for MD5 weak crypto usage
-			sample := testutils.SampleCodeinsecure-lib[0]
+			sample := testutils.SampleCodeInsecureLib[0]
 			source := sample.Code[0]
 			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
 
@@ -166,13 +403,13 @@ var _ = Describe("Analyzer", func() {
 			nosecPackage.Build()
 
 			analyzer.Process(buildTags, nosecPackage.Path)
-			nosecIssues, _, _ := analyzer.Report()
+			nosecIssues, _, _, _ := analyzer.Report()
 			Expect(nosecIssues).Should(BeEmpty())
 		})
 
 		It("should report errors when an exclude comment is present for a different rule", func() {
 			// Rule for MD5 weak crypto usage
-			sample := testutils.SampleCodeinsecure-lib[0]
+			sample := testutils.SampleCodeInsecureLib[0]
 			source := sample.Code[0]
 			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
 
@@ -183,13 +420,13 @@ var _ = Describe("Analyzer", func() {
 			nosecPackage.Build()
 
 			analyzer.Process(buildTags, nosecPackage.Path)
-			nosecIssues, _, _ := analyzer.Report()
+			nosecIssues, _, _, _ := analyzer.Report()
 			Expect(nosecIssues).Should(HaveLen(sample.Errors))
 		})
 
 		It("should not report errors when an exclude comment is present for multiple rules, including the correct rule", func() {
 			// Rule for MD5 weak crypto usage
-			sample := testutils.SampleCodeinsecure-lib[0]
+			sample := testutils.SampleCodeInsecureLib[0]
 			source := sample.Code[0]
 			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
 
@@ -200,10 +437,640 @@ var _ = Describe("Analyzer", func() {
 			nosecPackage.Build()
 
 			analyzer.Process(buildTags, nosecPackage.Path)
-			nosecIssues, _, _ := analyzer.Report()
+			nosecIssues, _, _, _ := analyzer.Report()
 			Expect(nosecIssues).Should(BeEmpty())
 		})
 
+		It("should not report errors when an exclude comment uses a legacy alias for the correct rule", func() {
+			// Rule for MD5 weak crypto usage
+			sample := testutils.SampleCodeInsecureLib[0]
+			source := sample.Code[0]
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec G401", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			nosecPackage.Build()
+
+			analyzer.Process(buildTags, nosecPackage.Path)
+			nosecIssues, _, _, _ := analyzer.Report()
+			Expect(nosecIssues).Should(BeEmpty())
+		})
+
+		It("should not report errors when an exclude comment lists comma separated rules, including the correct rule", func() {
+			// Rule for MD5 weak crypto usage
+			sample := testutils.SampleCodeInsecureLib[0]
+			source := sample.Code[0]
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec dir-perm,insecure-lib", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			nosecPackage.Build()
+
+			analyzer.Process(buildTags, nosecPackage.Path)
+			nosecIssues, metrics, _, _ := analyzer.Report()
+			Expect(nosecIssues).Should(BeEmpty())
+			Expect(metrics.NumNosec).Should(Equal(1))
+			Expect(metrics.NosecByRule).Should(HaveKeyWithValue("dir-perm", 1))
+			Expect(metrics.NosecByRule).Should(HaveKeyWithValue("insecure-lib", 1))
+		})
+
+		It("should count a nosec comment without rules against all rules", func() {
+			// Rule for MD5 weak crypto usage
+			sample := testutils.SampleCodeInsecureLib[0]
+			source := sample.Code[0]
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			nosecPackage.Build()
+
+			analyzer.Process(buildTags, nosecPackage.Path)
+			_, metrics, _, _ := analyzer.Report()
+			Expect(metrics.NosecByRule).Should(Equal(map[string]int{gosec.NosecAll: 1}))
+		})
+
+		It("should not report taint analysis issues when an exclude comment is present for the taint analysis", func() {
+			source := `
+				package main
+				import (
+					"net/http"
+					"os"
+				)
+				func handler(w http.ResponseWriter, r *http.Request) {
+					name := r.URL.Query().Get("name")
+					file := "/tmp/" + name
+					os.Open(file)
+				}
+				func main() {
+					http.HandleFunc("/", handler)
+				}`
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+
+			controlPackage := testutils.NewTestPackage()
+			defer controlPackage.Close()
+			controlPackage.AddFile("taint.go", source)
+			controlPackage.Build()
+			analyzer.Process(buildTags, controlPackage.Path)
+			controlIssues, _, _, _ := analyzer.Report()
+			Expect(controlIssues).Should(HaveLen(1))
+			Expect(controlIssues[0].RuleID).Should(Equal("taint-file-access"))
+
+			analyzer.Reset()
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, `os.Open(file)`, `os.Open(file) // #nosec TaintAnalysis`, 1)
+			nosecPackage.AddFile("taint.go", nosecSource)
+			nosecPackage.Build()
+			analyzer.Process(buildTags, nosecPackage.Path)
+			nosecIssues, _, _, _ := analyzer.Report()
+			Expect(nosecIssues).Should(BeEmpty())
+		})
+
+		It("should follow the taint through the functions of a package", func() {
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("input.go", `
+				package main
+				import "net/http"
+				func parseInput(r *http.Request) (string, error) {
+					return "/tmp/" + r.FormValue("name"), nil
+				}`)
+			pkg.AddFile("main.go", `
+				package main
+				import (
+					"net/http"
+					"os"
+				)
+				func open(path string) {
+					os.Open(path)
+				}
+				func handler(w http.ResponseWriter, r *http.Request) {
+					path, _ := parseInput(r)
+					open(path)
+					open("/tmp/static")
+				}
+				func main() {
+					http.HandleFunc("/", handler)
+				}`)
+			pkg.Build()
+			err := analyzer.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, _, _ := analyzer.Report()
+			Expect(issues).Should(HaveLen(1))
+			Expect(issues[0].RuleID).Should(Equal("taint-file-access"))
+			Expect(issues[0].Code).Should(Equal("open(path)"))
+			Expect(issues[0].What).Should(Equal("User input used by os.Open before validation"))
+
+			var steps []string
+			for _, step := range issues[0].Trace {
+				steps = append(steps, step.What+": "+step.Code)
+			}
+			Expect(steps).Should(Equal([]string{
+				"user input in parameter r: r",
+				`returned: return "/tmp/" + r.FormValue("name"), nil`,
+				"assigned to path: path, _ := parseInput(r)",
+				"passed to open: open(path)",
+				"parameter path: path",
+				"used by os.Open: os.Open(path)",
+			}))
+		})
+
+		It("should follow the taint through fields, elements, loops and closures", func() {
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("main.go", `
+				package main
+				import (
+					"io/ioutil"
+					"net/http"
+					"os"
+					"os/exec"
+				)
+				type input struct {
+					Path string
+					Mode string
+				}
+				func handler(w http.ResponseWriter, r *http.Request) {
+					in := input{Path: r.FormValue("path"), Mode: "ro"}
+					os.Open(in.Mode)
+					os.Open(in.Path)
+					names := map[string]string{}
+					names["a"] = r.URL.Query().Get("name")
+					for key, name := range names {
+						os.Remove(name)
+						os.RemoveAll(key)
+					}
+					if dir := r.FormValue("dir"); dir != "" {
+						os.Mkdir(dir, 0700)
+					}
+					p := &in
+					p.Mode = r.FormValue("mode")
+					os.Create(in.Mode)
+					in.Mode = "rw"
+					os.Create(in.Mode)
+					go func() {
+						exec.Command(in.Path).Run()
+					}()
+					run := func(name string) {
+						exec.Command("ls", name).Run()
+					}
+					run("/tmp")
+					run(in.Path)
+				}
+				func main() {
+					http.HandleFunc("/", handler)
+					http.HandleFunc("/read", func(w http.ResponseWriter, req *http.Request) {
+						ioutil.ReadFile(req.URL.Path)
+					})
+				}`)
+			pkg.Build()
+			err := analyzer.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, _, _ := analyzer.Report()
+
+			var codes []string
+			for _, issue := range issues {
+				codes = append(codes, issue.Code)
+			}
+			Expect(codes).Should(ConsistOf(
+				"os.Open(in.Path)",
+				"os.Remove(name)",
+				"os.RemoveAll(key)",
+				"os.Mkdir(dir, 0700)",
+				"os.Create(in.Mode)",
+				"exec.Command(in.Path)",
+				`exec.Command("ls", name)`,
+				"ioutil.ReadFile(req.URL.Path)",
+			))
+		})
+
+		Context("when checking each built-in source", func() {
+			// the sources are checked in one module, whose web frameworks are
+			// replaced with stubs, and which is only analyzed once
+			var codes []string
+			taintedCodes := func() []string {
+				if codes != nil {
+					return codes
+				}
+				analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+				dir, err := ioutil.TempDir("", "gosec_module")
+				Expect(err).ShouldNot(HaveOccurred())
+				defer os.RemoveAll(dir)
+				gomod := "module example.com/app\n"
+				for path, content := range map[string]string{
+					"github.com/gin-gonic/gin": `
+						package gin
+						type Context struct{}
+						func (c *Context) Query(key string) string { return "" }
+						type HandlerFunc func(*Context)
+						type Engine struct{}
+						func Default() *Engine { return &Engine{} }
+						func (e *Engine) GET(path string, handlers ...HandlerFunc) {}`,
+					"github.com/labstack/echo": `
+						package echo
+						type Context interface {
+							Param(name string) string
+						}
+						type HandlerFunc func(Context) error
+						type Echo struct{}
+						func New() *Echo { return &Echo{} }
+						func (e *Echo) GET(path string, h HandlerFunc) {}`,
+				} {
+					local := filepath.Join(dir, "frameworks", filepath.Base(path))
+					Expect(os.MkdirAll(local, 0755)).Should(Succeed())
+					Expect(ioutil.WriteFile(filepath.Join(local, "go.mod"), []byte("module "+path+"\n"), 0644)).Should(Succeed())
+					Expect(ioutil.WriteFile(filepath.Join(local, "framework.go"), []byte(content), 0644)).Should(Succeed())
+					gomod += "require " + path + " v0.0.0\nreplace " + path + " => ./frameworks/" + filepath.Base(path) + "\n"
+				}
+				Expect(ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0644)).Should(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(`
+					package main
+					import (
+						"bufio"
+						"context"
+						"flag"
+						"io/ioutil"
+						"net"
+						"net/http"
+						"net/url"
+						"os"
+						"os/exec"
+						"strings"
+						"github.com/gin-gonic/gin"
+						"github.com/labstack/echo"
+					)
+					type HelloRequest struct {
+						Name string
+					}
+					func (*HelloRequest) ProtoMessage() {}
+					type HelloReply struct{}
+					type server struct{}
+					func (s *server) SayHello(ctx context.Context, req *HelloRequest) (*HelloReply, error) {
+						os.Open(req.Name)
+						return &HelloReply{}, nil
+					}
+					func (s *server) create(req *HelloRequest) {
+						os.Create(req.Name)
+					}
+					func serve(ln net.Listener) {
+						conn, _ := ln.Accept()
+						line, _ := bufio.NewReader(conn).ReadString('\n')
+						exec.Command(line)
+						local := bufio.NewReader(strings.NewReader("date"))
+						name, _ := local.ReadString('\n')
+						exec.Command(name)
+					}
+					func remove(v url.Values) {
+						os.Remove(v.Get("file"))
+						os.Remove("/tmp/file")
+					}
+					func main() {
+						dir := flag.String("dir", "/tmp", "directory")
+						var file string
+						flag.StringVar(&file, "file", "", "file")
+						verbose := flag.Bool("v", false, "verbose")
+						flag.Parse()
+						os.Mkdir(*dir, 0700)
+						os.Remove(file)
+						os.RemoveAll(flag.Arg(0))
+						if *verbose {
+							os.Create("/tmp/log")
+						}
+						ioutil.ReadFile(os.Args[1])
+						ioutil.ReadFile(os.DevNull)
+						ioutil.WriteFile(os.Getenv("OUT"), nil, 0600)
+						ioutil.WriteFile(os.TempDir(), nil, 0600)
+						http.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+							os.RemoveAll(r.FormValue("dir"))
+							os.RemoveAll("/tmp/dir")
+						}))
+						router := gin.Default()
+						router.GET("/", func(c *gin.Context) {
+							exec.Command(c.Query("cmd"))
+							exec.Command("date")
+						})
+						e := echo.New()
+						e.GET("/", func(c echo.Context) error {
+							os.Open(c.Param("file"))
+							_, err := os.Open("/tmp/file")
+							return err
+						})
+					}`), 0644)).Should(Succeed())
+
+				err = analyzer.Process(buildTags, dir)
+				Expect(err).ShouldNot(HaveOccurred())
+				issues, _, _, _ := analyzer.Report()
+				codes = []string{}
+				for _, issue := range issues {
+					codes = append(codes, issue.Code)
+				}
+				return codes
+			}
+
+			It("should find the request message of a gRPC handler", func() {
+				Expect(taintedCodes()).Should(ContainElement("os.Open(req.Name)"))
+				Expect(taintedCodes()).ShouldNot(ContainElement("os.Create(req.Name)"))
+			})
+
+			It("should find the command line arguments of os.Args", func() {
+				Expect(taintedCodes()).Should(ContainElement("ioutil.ReadFile(os.Args[1])"))
+				Expect(taintedCodes()).ShouldNot(ContainElement("ioutil.ReadFile(os.DevNull)"))
+			})
+
+			It("should find the flags and the arguments of the flag package", func() {
+				Expect(taintedCodes()).Should(ContainElement("os.Mkdir(*dir, 0700)"))
+				Expect(taintedCodes()).Should(ContainElement("os.Remove(file)"))
+				Expect(taintedCodes()).Should(ContainElement("os.RemoveAll(flag.Arg(0))"))
+				Expect(taintedCodes()).ShouldNot(ContainElement(`os.Create("/tmp/log")`))
+			})
+
+			It("should find the environment variables of os.Getenv", func() {
+				Expect(taintedCodes()).Should(ContainElement(`ioutil.WriteFile(os.Getenv("OUT"), nil, 0600)`))
+				Expect(taintedCodes()).ShouldNot(ContainElement("ioutil.WriteFile(os.TempDir(), nil, 0600)"))
+			})
+
+			It("should find the data read from a network connection", func() {
+				Expect(taintedCodes()).Should(ContainElement("exec.Command(line)"))
+				Expect(taintedCodes()).ShouldNot(ContainElement("exec.Command(name)"))
+			})
+
+			It("should find the values of url.Values", func() {
+				Expect(taintedCodes()).Should(ContainElement(`os.Remove(v.Get("file"))`))
+				Expect(taintedCodes()).ShouldNot(ContainElement(`os.Remove("/tmp/file")`))
+			})
+
+			It("should find the requests of the http.HandlerFunc handlers", func() {
+				Expect(taintedCodes()).Should(ContainElement(`os.RemoveAll(r.FormValue("dir"))`))
+				Expect(taintedCodes()).ShouldNot(ContainElement(`os.RemoveAll("/tmp/dir")`))
+			})
+
+			It("should find the contexts of the gin handlers", func() {
+				Expect(taintedCodes()).Should(ContainElement(`exec.Command(c.Query("cmd"))`))
+				Expect(taintedCodes()).ShouldNot(ContainElement(`exec.Command("date")`))
+			})
+
+			It("should find the contexts of the echo handlers", func() {
+				Expect(taintedCodes()).Should(ContainElement(`os.Open(c.Param("file"))`))
+				Expect(taintedCodes()).ShouldNot(ContainElement(`os.Open("/tmp/file")`))
+			})
+		})
+
+		It("should follow the taint across the scanned packages", func() {
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+			dir, err := ioutil.TempDir("", "gosec_module")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			Expect(os.Mkdir(filepath.Join(dir, "helpers"), 0755)).Should(Succeed())
+			files := map[string]string{
+				"go.mod": "module example.com/app\n",
+				"main.go": `
+				package main
+				import (
+					"net/http"
+					"example.com/app/helpers"
+				)
+				func handler(w http.ResponseWriter, r *http.Request) {
+					helpers.Run(helpers.Param(r, "cmd"))
+					helpers.Run("date")
+				}
+				func main() {
+					http.HandleFunc("/", handler)
+				}`,
+				filepath.Join("helpers", "helpers.go"): `
+				package helpers
+				import (
+					"net/http"
+					"os/exec"
+				)
+				func Param(r *http.Request, key string) string {
+					return r.URL.Query().Get(key)
+				}
+				func Run(name string) error {
+					return exec.Command("/bin/echo", name).Run()
+				}`,
+			}
+			for name, content := range files {
+				Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).Should(Succeed())
+			}
+
+			err = analyzer.Process(buildTags, dir, filepath.Join(dir, "helpers"))
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, _, _ := analyzer.Report()
+			Expect(issues).Should(HaveLen(1))
+			Expect(issues[0].Code).Should(Equal(`helpers.Run(helpers.Param(r, "cmd"))`))
+		})
+
+		It("should use the taint sources, sanitizers and sinks of the configuration", func() {
+			taintConfig := gosec.NewConfig()
+			taintConfig.Set(gosec.TaintAnalysisID, map[string]interface{}{
+				"sources": []interface{}{
+					map[string]interface{}{"package": "example.com/app/pb"},
+				},
+				"sanitizers": []interface{}{
+					map[string]interface{}{"package": "example.com/app/check", "constructor": "New", "functions": []interface{}{"Request"}},
+				},
+				"sinks": []interface{}{
+					map[string]interface{}{"package": "example.com/app/store", "receiver": "Store", "functions": []interface{}{"Raw"}, "args": []interface{}{0}, "rule_id": "taint-store", "severity": "HIGH"},
+				},
+			})
+			customAnalyzer := gosec.NewAnalyzer(taintConfig, logger, false)
+			customAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+
+			dir, err := ioutil.TempDir("", "gosec_module")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+			for _, pkg := range []string{"pb", "check", "store"} {
+				Expect(os.Mkdir(filepath.Join(dir, pkg), 0755)).Should(Succeed())
+			}
+			files := map[string]string{
+				"go.mod": "module example.com/app\n",
+				"main.go": `
+				package main
+				import (
+					"context"
+					"example.com/app/check"
+					"example.com/app/pb"
+					"example.com/app/store"
+				)
+				type server struct {
+					db *store.Store
+				}
+				func (s *server) Create(ctx context.Context, req *pb.CreateRequest) error {
+					return s.db.Raw(req.Name, req.Name)
+				}
+				func (s *server) Update(ctx context.Context, req *pb.UpdateRequest) error {
+					v := check.New()
+					err := v.Request(req)
+					if err != nil {
+						return err
+					}
+					return s.db.Raw(req.Name)
+				}
+				func main() {}`,
+				filepath.Join("pb", "pb.go"): `
+				package pb
+				type CreateRequest struct {
+					Name string
+				}
+				type UpdateRequest struct {
+					Name string
+				}`,
+				filepath.Join("check", "check.go"): `
+				package check
+				type Validator struct{}
+				func New() *Validator {
+					return &Validator{}
+				}
+				func (v *Validator) Request(req interface{}) error {
+					return nil
+				}`,
+				filepath.Join("store", "store.go"): `
+				package store
+				type Store struct{}
+				func (s *Store) Raw(query string, args ...interface{}) error {
+					return nil
+				}`,
+			}
+			for name, content := range files {
+				Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).Should(Succeed())
+			}
+
+			err = customAnalyzer.Process(buildTags, dir)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, _, _, _ := customAnalyzer.Report()
+			Expect(issues).Should(HaveLen(1))
+			Expect(issues[0].RuleID).Should(Equal("taint-store"))
+			Expect(issues[0].Severity).Should(Equal(gosec.High))
+			Expect(issues[0].Code).Should(Equal("s.db.Raw(req.Name, req.Name)"))
+		})
+
+		It("should apply the settings of the rules section to the issues", func() {
+			sample := testutils.SampleCodeInsecureLib[0]
+			ruleConfig := gosec.NewConfig()
+			ruleConfig.Set(gosec.Rules, map[string]interface{}{
+				"G401":              map[string]interface{}{"severity": "HIGH", "confidence": "LOW", "message": "MD5 is not allowed"},
+				"taint-file-path":   map[string]interface{}{"enabled": false},
+				"taint-file-access": map[string]interface{}{"enabled": false},
+			})
+			ruleAnalyzer := gosec.NewAnalyzer(ruleConfig, logger, false)
+			ruleAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib", "taint-file-path")).Builders())
+
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("md5.go", sample.Code[0])
+			pkg.AddFile("read.go", `
+				package main
+				import (
+					"io/ioutil"
+					"net/http"
+				)
+				func handler(w http.ResponseWriter, r *http.Request) {
+					data, _ := ioutil.ReadFile(r.URL.Query().Get("file"))
+					w.Write(data)
+				}`)
+			pkg.Build()
+
+			err := ruleAnalyzer.Process(buildTags, pkg.Path)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, metrics, _, _ := ruleAnalyzer.Report()
+			Expect(issues).ShouldNot(BeEmpty())
+			Expect(metrics.NumFound).Should(Equal(len(issues)))
+			for _, issue := range issues {
+				Expect(issue.RuleID).Should(Equal("insecure-lib"))
+				Expect(issue.Severity).Should(Equal(gosec.High))
+				Expect(issue.Confidence).Should(Equal(gosec.Low))
+				Expect(issue.What).Should(Equal("MD5 is not allowed"))
+			}
+		})
+
+		It("should take the text of a nosec comment which names no rule as its justification", func() {
+			// Rule for MD5 weak crypto usage
+			sample := testutils.SampleCodeInsecureLib[0]
+			source := sample.Code[0]
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec this is safe", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			nosecPackage.Build()
+
+			analyzer.Process(buildTags, nosecPackage.Path)
+			nosecIssues, metrics, _, suppressions := analyzer.Report()
+			Expect(nosecIssues).Should(BeEmpty())
+			Expect(metrics.NosecByRule).Should(Equal(map[string]int{gosec.NosecAll: 1}))
+			Expect(suppressions).Should(HaveLen(1))
+			Expect(suppressions[0].RuleIDs).Should(BeEmpty())
+			Expect(suppressions[0].Justification).Should(Equal("this is safe"))
+		})
+
+		It("should only read the nosec directives at the start of a comment", func() {
+			// Rule for MD5 weak crypto usage
+			sample := testutils.SampleCodeInsecureLib[0]
+			source := sample.Code[0]
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // a #nosec comment here would hide it", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			nosecPackage.Build()
+
+			analyzer.Process(buildTags, nosecPackage.Path)
+			nosecIssues, metrics, _, suppressions := analyzer.Report()
+			Expect(nosecIssues).Should(HaveLen(sample.Errors))
+			Expect(metrics.NumNosec).Should(BeZero())
+			Expect(suppressions).Should(BeEmpty())
+		})
+
+		It("should accept the IDs of the rules which are not loaded in a nosec comment", func() {
+			// Rule for MD5 weak crypto usage
+			sample := testutils.SampleCodeInsecureLib[0]
+			source := sample.Code[0]
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+			analyzer.AddRuleIDs(rules.Generate().IDs()...)
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec slowloris", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			nosecPackage.Build()
+
+			analyzer.Process(buildTags, nosecPackage.Path)
+			nosecIssues, metrics, _, _ := analyzer.Report()
+			Expect(nosecIssues).Should(HaveLen(sample.Errors))
+			Expect(metrics.NosecByRule).Should(Equal(map[string]int{"slowloris": 1}))
+		})
+
+		It("should report the suppressions with their justification", func() {
+			// Rule for MD5 weak crypto usage
+			sample := testutils.SampleCodeInsecureLib[0]
+			source := sample.Code[0]
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+
+			nosecPackage := testutils.NewTestPackage()
+			defer nosecPackage.Close()
+			nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec G401 -- checksum only", 1)
+			nosecPackage.AddFile("md5.go", nosecSource)
+			nosecPackage.Build()
+
+			analyzer.Process(buildTags, nosecPackage.Path)
+			nosecIssues, _, _, suppressions := analyzer.Report()
+			Expect(nosecIssues).Should(BeEmpty())
+			Expect(suppressions).Should(HaveLen(1))
+			Expect(suppressions[0].File).Should(HaveSuffix("md5.go"))
+			Expect(suppressions[0].RuleIDs).Should(Equal([]string{"insecure-lib"}))
+			Expect(suppressions[0].Justification).Should(Equal("checksum only"))
+		})
+
 		It("should pass the build tags", func() {
 			sample := testutils.SampleCode601[0]
 			source := sample.Code[0]
@@ -218,16 +1085,50 @@ var _ = Describe("Analyzer", func() {
 		})
 	})
 
+	It("should report nosec comments without a justification when one is required", func() {
+
+		// Rule for MD5 weak crypto usage
+		sample := testutils.SampleCodeInsecureLib[0]
+		source := sample.Code[0]
+
+		justifyConfig := gosec.NewConfig()
+		justifyConfig.SetGlobal(gosec.NosecJustification, "enabled")
+		customAnalyzer := gosec.NewAnalyzer(justifyConfig, logger, false)
+		customAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
+
+		nosecPackage := testutils.NewTestPackage()
+		defer nosecPackage.Close()
+		nosecSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec insecure-lib", 1)
+		nosecPackage.AddFile("md5.go", nosecSource)
+		nosecPackage.Build()
+
+		customAnalyzer.Process(buildTags, nosecPackage.Path)
+		nosecIssues, _, _, _ := customAnalyzer.Report()
+		Expect(nosecIssues).Should(HaveLen(1))
+		Expect(nosecIssues[0].RuleID).Should(Equal(gosec.NosecJustificationID))
+
+		justifiedPackage := testutils.NewTestPackage()
+		defer justifiedPackage.Close()
+		justifiedSource := strings.Replace(source, "h := md5.New()", "h := md5.New() // #nosec insecure-lib -- checksum only", 1)
+		justifiedPackage.AddFile("md5.go", justifiedSource)
+		justifiedPackage.Build()
+
+		customAnalyzer.Reset()
+		customAnalyzer.Process(buildTags, justifiedPackage.Path)
+		justifiedIssues, _, _, _ := customAnalyzer.Report()
+		Expect(justifiedIssues).Should(BeEmpty())
+	})
+
 	It("should be possible to overwrite nosec comments, and report issues", func() {
 
 		// Rule for MD5 weak crypto usage
-		sample := testutils.SampleCodeinsecure-lib[0]
+		sample := testutils.SampleCodeInsecureLib[0]
 		source := sample.Code[0]
 
 		// overwrite nosec option
 		nosecIgnoreConfig := gosec.NewConfig()
 		nosecIgnoreConfig.SetGlobal(gosec.Nosec, "true")
-		customAnalyzer := gosec.NewAnalyzer(nosecIgnoreConfig, logger)
+		customAnalyzer := gosec.NewAnalyzer(nosecIgnoreConfig, logger, false)
 		customAnalyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "insecure-lib")).Builders())
 
 		nosecPackage := testutils.NewTestPackage()
@@ -237,7 +1138,7 @@ var _ = Describe("Analyzer", func() {
 		nosecPackage.Build()
 
 		customAnalyzer.Process(buildTags, nosecPackage.Path)
-		nosecIssues, _, _ := customAnalyzer.Report()
+		nosecIssues, _, _, _ := customAnalyzer.Report()
 		Expect(nosecIssues).Should(HaveLen(sample.Errors))
 
 	})
@@ -0,0 +1,185 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package analyzers exposes the gosec rules and the taint analysis as
+// go/analysis analyzers, so that they run in any checker driver, e.g. a
+// multichecker, next to the other linters.
+//
+// The Taint analyzer checks each package once with all the rules. It
+// passes the taint summaries of the functions to the importing packages as
+// facts, and reports the issues of the taint analysis sinks which are not
+// rules. The analyzer of a rule reports the issues of its rule ID. All the
+// analyzers share the -config flag, which gives a gosec configuration file.
+//
+// This package is a separate module, github.com/securego/gosec/analyzers, on
+// go 1.26.0 and golang.org/x/tools v0.50.0, which provides go/analysis. The
+// gosec module itself stays on the 2017 revision of x/tools and its
+// go/loader, and the go.mod of this module replaces it with the parent
+// directory, so the gosec packages build against both x/tools versions.
+package analyzers
+
+import (
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/securego/gosec"
+	"github.com/securego/gosec/rules"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+)
+
+// configFile is the gosec configuration file given with the -config flag
+// of any of the analyzers
+var configFile string
+
+// Taint runs the rules and the taint analysis over a package. Its result
+// holds the issues of all the rules, which the analyzers of the rules
+// report.
+var Taint = &analysis.Analyzer{
+	Name:       "gosec_taint",
+	Doc:        "reports the user input reaching a sink before it is validated",
+	Run:        runTaint,
+	Requires:   []*analysis.Analyzer{buildssa.Analyzer},
+	ResultType: reflect.TypeOf([]*gosec.Issue(nil)),
+	FactTypes:  []analysis.Fact{new(taintFact)},
+}
+
+// taintFact holds the taint summary of a function
+type taintFact gosec.TaintFact
+
+func (*taintFact) AFact() {}
+
+func (f *taintFact) String() string {
+	return "taint"
+}
+
+var analyzers []*analysis.Analyzer
+
+func init() {
+	list := rules.Generate()
+	ids := make([]string, 0, len(list))
+	for id := range list {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		analyzers = append(analyzers, newRuleAnalyzer(list[id]))
+	}
+	analyzers = append(analyzers, Taint)
+	for _, a := range analyzers {
+		a.Flags.StringVar(&configFile, "config", "", "Path to a gosec configuration file")
+	}
+}
+
+// Analyzers returns the analyzers of the rules, sorted by rule ID, followed
+// by the Taint analyzer
+func Analyzers() []*analysis.Analyzer {
+	return analyzers
+}
+
+// newRuleAnalyzer creates the analyzer reporting the issues of a rule
+func newRuleAnalyzer(def rules.RuleDefinition) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name:     "gosec_" + strings.Replace(def.ID, "-", "_", -1),
+		Doc:      def.Description,
+		Requires: []*analysis.Analyzer{Taint},
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			for _, issue := range pass.ResultOf[Taint].([]*gosec.Issue) {
+				if issue.RuleID == def.ID {
+					report(pass, issue)
+				}
+			}
+			return nil, nil
+		},
+	}
+}
+
+func runTaint(pass *analysis.Pass) (interface{}, error) {
+	config := gosec.NewConfig()
+	if configFile != "" {
+		// #nosec taint-file-path -- the configuration is given on the command line
+		file, err := os.Open(configFile)
+		if err != nil {
+			return nil, err
+		}
+		_, err = config.ReadFrom(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	analyzer := gosec.NewAnalyzer(config, log.New(ioutil.Discard, "", 0), false)
+	list := rules.Generate()
+	analyzer.LoadRules(list.Builders())
+
+	ssaPkg := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA).Pkg
+	facts := analyzer.CheckTypedPackage(pass.Fset, pass.Files, pass.Pkg, pass.TypesInfo, ssaPkg, func(fn *types.Func) *gosec.TaintFact {
+		var fact taintFact
+		if pass.ImportObjectFact(fn.Origin(), &fact) {
+			return (*gosec.TaintFact)(&fact)
+		}
+		return nil
+	})
+	for fn, fact := range facts {
+		pass.ExportObjectFact(fn, (*taintFact)(fact))
+	}
+
+	issues, _, _, _ := analyzer.Report()
+	for _, issue := range issues {
+		if _, ok := list[issue.RuleID]; !ok {
+			report(pass, issue)
+		}
+	}
+	return issues, nil
+}
+
+// report reports an issue at the start of its first line, with the steps
+// of its flow as related information
+func report(pass *analysis.Pass, issue *gosec.Issue) {
+	pos := position(pass, issue.File, issue.Line)
+	if !pos.IsValid() {
+		return
+	}
+	diagnostic := analysis.Diagnostic{
+		Pos:      pos,
+		Category: issue.RuleID,
+		Message:  issue.What + " (" + issue.RuleID + ")",
+	}
+	for _, step := range issue.Trace {
+		if pos := position(pass, step.File, step.Line); pos.IsValid() {
+			diagnostic.Related = append(diagnostic.Related, analysis.RelatedInformation{Pos: pos, Message: step.What})
+		}
+	}
+	pass.Report(diagnostic)
+}
+
+// position returns the start of the first line of a range, e.g. "12" or
+// "12-14", in a file of the package
+func position(pass *analysis.Pass, filename, lines string) token.Pos {
+	line, err := strconv.Atoi(strings.SplitN(lines, "-", 2)[0])
+	if err != nil {
+		return token.NoPos
+	}
+	for _, file := range pass.Files {
+		if tf := pass.Fset.File(file.Pos()); tf != nil && tf.Name() == filename && line <= tf.LineCount() {
+			return tf.LineStart(line)
+		}
+	}
+	return token.NoPos
+}
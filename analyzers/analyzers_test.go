@@ -0,0 +1,33 @@
+package analyzers_test
+
+import (
+	"testing"
+
+	"github.com/securego/gosec/analyzers"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func analyzer(name string) *analysis.Analyzer {
+	for _, a := range analyzers.Analyzers() {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func TestAnalyzers(t *testing.T) {
+	if err := analysis.Validate(analyzers.Analyzers()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRule(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzer("gosec_insecure_lib"), "weak")
+	analysistest.Run(t, analysistest.TestData(), analyzer("gosec_blacklist_md5"), "weak")
+}
+
+func TestTaintFacts(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), analyzers.Taint, "handler")
+}
@@ -0,0 +1,16 @@
+module github.com/securego/gosec/analyzers
+
+go 1.26.0
+
+require (
+	github.com/securego/gosec v0.0.0
+	golang.org/x/tools v0.50.0
+)
+
+require (
+	github.com/nbutton23/zxcvbn-go v0.0.0-20160627004424-a22cb81b2ecd // indirect
+	golang.org/x/mod v0.41.0 // indirect
+	golang.org/x/sync v0.23.0 // indirect
+)
+
+replace github.com/securego/gosec => ../
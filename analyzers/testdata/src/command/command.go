@@ -0,0 +1,8 @@
+package command
+
+import "os/exec"
+
+// Run runs a shell command
+func Run(command string) error {
+	return exec.Command("sh", "-c", command).Run() // want "Subprocess launched with variable"
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	"command"
+)
+
+func Handle(w http.ResponseWriter, r *http.Request) {
+	command.Run(r.FormValue("cmd")) // want "User input used by command.Run before validation"
+	command.Run("ls")
+}
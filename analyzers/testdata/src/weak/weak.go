@@ -0,0 +1,14 @@
+package weak
+
+import (
+	"crypto/md5" // want "Blacklisted import crypto/md5: weak cryptographic primitive"
+	"fmt"
+)
+
+func Hash(data []byte) {
+	fmt.Printf("%x\n", md5.Sum(data)) // want "Use of weak cryptographic primitive"
+}
+
+func Audited(data []byte) [16]byte {
+	return md5.Sum(data) // #nosec insecure-lib -- checksum of a public file
+}
@@ -0,0 +1,314 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cacheVersion is changed when the format of the cached results changes
+const cacheVersion = "2"
+
+// resultCache stores the results of the packages on disk, so that the
+// packages whose inputs did not change are not checked again
+type resultCache struct {
+	dir string
+	// settings is the hash of the inputs shared by all the packages: the
+	// gosec executable, the Go toolchain, the enabled rules and the
+	// configuration
+	settings string
+}
+
+// cacheEntry holds the results of a package
+type cacheEntry struct {
+	Issues       []*Issue       `json:"issues"`
+	Metrics      *Metrics       `json:"metrics"`
+	Suppressions []*Suppression `json:"suppressions"`
+	// Errors are the golang errors of the package and of its imports
+	Errors map[string][]Error `json:"errors"`
+}
+
+// newResultCache creates a cache in the given directory for the results
+// of the analyzer
+func newResultCache(dir string, gosec *Analyzer) (*resultCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "version %s\n", cacheVersion)
+	if executable, err := os.Executable(); err == nil {
+		// #nosec taint-file-path -- the path of the running executable
+		if file, err := os.Open(executable); err == nil {
+			_, err = io.Copy(hash, file)
+			file.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	fmt.Fprintf(hash, "go %s %s\n", runtime.Version(), build.Default.GOROOT)
+	for _, id := range gosec.ruleIDs() {
+		fmt.Fprintf(hash, "rule %s\n", id)
+	}
+	fmt.Fprintf(hash, "taint %t\n", !gosec.noTaintAnalysis)
+	config, err := configHash(gosec.config)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(hash, "config %s\n", config)
+	return &resultCache{dir: dir, settings: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// ruleIDs returns the sorted IDs of the loaded rules
+func (gosec *Analyzer) ruleIDs() []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, rules := range gosec.ruleset {
+		for _, rule := range rules {
+			if !seen[rule.ID()] {
+				seen[rule.ID()] = true
+				ids = append(ids, rule.ID())
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// configHash hashes the configuration, leaving out the global options
+// which do not change the results
+func configHash(config Config) (string, error) {
+	settings := make(Config, len(config))
+	for key, value := range config {
+		settings[key] = value
+	}
+	if globals, ok := config[Globals].(map[GlobalOption]string); ok {
+		options := make(map[GlobalOption]string, len(globals))
+		for option, value := range globals {
+			if option != CacheDir && option != Concurrency {
+				options[option] = value
+			}
+		}
+		settings[Globals] = options
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sourceHasher hashes the sources a package depends on, before the
+// packages are loaded. The packages of the standard library are covered by
+// the Go version and the packages of the module cache by their versioned
+// directory, so that only the files of the local packages are read.
+type sourceHasher struct {
+	ctx      *build.Context
+	modCache string
+	// hashes are the hashes of the imported packages by directory
+	hashes map[string]string
+}
+
+func newSourceHasher(ctx *build.Context) *sourceHasher {
+	modCache := os.Getenv("GOMODCACHE")
+	if modCache == "" {
+		if gopaths := filepath.SplitList(ctx.GOPATH); len(gopaths) > 0 {
+			modCache = filepath.Join(gopaths[0], "pkg", "mod")
+		}
+	}
+	return &sourceHasher{ctx: ctx, modCache: modCache, hashes: make(map[string]string)}
+}
+
+// key returns the cache key of a package, which covers the content of its
+// files and of the files of its local dependencies, as the taint summaries
+// of the functions depend on their code. Within a module, the go.mod and
+// go.sum files select the versions of the other dependencies. It returns an
+// empty key if a file cannot be read or an import cannot be resolved.
+func (c *resultCache) key(hasher *sourceHasher, pkg *scanPackage) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "settings %s\n", c.settings)
+	fmt.Fprintf(hash, "package %s %s\n", pkg.name, pkg.dir)
+	if hasher.ctx.Dir != "" {
+		for _, name := range []string{"go.mod", "go.sum"} {
+			// #nosec taint-file-path -- a file of the scanned module
+			data, err := ioutil.ReadFile(filepath.Join(hasher.ctx.Dir, name))
+			if err != nil && !os.IsNotExist(err) {
+				return ""
+			}
+			fmt.Fprintf(hash, "%s %d\n", name, len(data))
+			hash.Write(data)
+		}
+	}
+	sources := hasher.files(pkg.dir, pkg.files)
+	if sources == "" {
+		return ""
+	}
+	fmt.Fprintf(hash, "sources %s\n", sources)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// files hashes the given files of a package and the hashes of the packages
+// they import
+func (h *sourceHasher) files(dir string, files []string) string {
+	hash := sha256.New()
+	imports := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, filename := range files {
+		// #nosec taint-file-path -- a file of a scanned or imported package
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return ""
+		}
+		fmt.Fprintf(hash, "file %s %d\n", filename, len(data))
+		hash.Write(data)
+		file, err := parser.ParseFile(fset, filename, data, parser.ImportsOnly)
+		if err != nil {
+			return ""
+		}
+		for _, spec := range file.Imports {
+			if path, err := strconv.Unquote(spec.Path.Value); err == nil && path != "C" {
+				imports[path] = true
+			}
+		}
+	}
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		imported := h.imported(path, dir)
+		if imported == "" {
+			return ""
+		}
+		fmt.Fprintf(hash, "import %s %s\n", path, imported)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// imported returns the hash of an imported package
+func (h *sourceHasher) imported(path, srcDir string) string {
+	bp, err := h.ctx.Import(path, srcDir, build.FindOnly)
+	if err != nil {
+		return ""
+	}
+	if bp.Goroot {
+		return "goroot"
+	}
+	if h.modCache != "" && strings.HasPrefix(bp.Dir, h.modCache+string(filepath.Separator)) {
+		return "module " + bp.Dir
+	}
+	if hash, ok := h.hashes[bp.Dir]; ok {
+		return hash
+	}
+	// an import cycle does not type check, the package is not cached
+	h.hashes[bp.Dir] = ""
+	bp, err = h.ctx.ImportDir(bp.Dir, 0)
+	if err != nil {
+		return ""
+	}
+	var files []string
+	for _, name := range append(append([]string{}, bp.GoFiles...), bp.CgoFiles...) {
+		files = append(files, filepath.Join(bp.Dir, name))
+	}
+	h.hashes[bp.Dir] = h.files(bp.Dir, files)
+	return h.hashes[bp.Dir]
+}
+
+func (c *resultCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// load returns the results stored for a key, or nil if there are none
+func (c *resultCache) load(key string) *cacheEntry {
+	// #nosec taint-file-path -- the key is a hash
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Metrics == nil {
+		return nil
+	}
+	if entry.Metrics.NosecByRule == nil {
+		entry.Metrics.NosecByRule = make(map[string]int)
+	}
+	return &entry
+}
+
+// store saves the results of a package. The entry is renamed into place,
+// so that concurrent scans never read a partial entry.
+func (c *resultCache) store(key string, entry *cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(c.dir, key+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+// lookup computes the cache keys of the scanned packages before they are
+// loaded, and takes the results of the packages found in the cache. The
+// keys stay empty for the packages which cannot be cached.
+func (gosec *Analyzer) lookup(ctx *build.Context, packages []*scanPackage) {
+	if gosec.cache == nil {
+		return
+	}
+	hasher := newSourceHasher(ctx)
+	for _, pkg := range packages {
+		pkg.key = gosec.cache.key(hasher, pkg)
+		if pkg.key == "" {
+			continue
+		}
+		if entry := gosec.cache.load(pkg.key); entry != nil {
+			gosec.logger.Println("Using cached results:", pkg.dir)
+			worker := gosec.fork()
+			worker.issues = entry.Issues
+			worker.stats = entry.Metrics
+			worker.suppressions = entry.Suppressions
+			worker.errors = entry.Errors
+			pkg.result = worker
+			gosec.stats.CacheHits++
+		} else {
+			gosec.stats.CacheMisses++
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/securego/gosec"
+)
+
+// baselineReport is the content of a baseline file. It has the layout of a
+// json report, so that a json report can be used as a baseline.
+type baselineReport struct {
+	Issues []*gosec.Issue
+}
+
+// baseline counts the fingerprints of the issues accepted in a previous
+// report. An issue found several times is matched as many times.
+type baseline map[string]int
+
+// writeBaseline saves the issues to a baseline file. The file paths are
+// stored relative to the root path, so that the baseline can be committed
+// with the code and used from any checkout.
+func writeBaseline(filename, rootPath string, issues []*gosec.Issue) error {
+	report := baselineReport{Issues: make([]*gosec.Issue, 0, len(issues))}
+	for _, issue := range issues {
+		rel, err := baselinePath(rootPath, issue.File)
+		if err != nil {
+			return err
+		}
+		accepted := *issue
+		accepted.File = rel
+		// the trace does not identify the issue
+		accepted.Trace = nil
+		report.Issues = append(report.Issues, &accepted)
+	}
+	data, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// loadBaseline reads the issues of a baseline file, or of a report in the
+// json format
+func loadBaseline(filename, rootPath string) (baseline, error) {
+	// #nosec taint-file-path -- the baseline is given on the command line
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var report baselineReport
+	if err := json.NewDecoder(file).Decode(&report); err != nil {
+		return nil, err
+	}
+	b := make(baseline)
+	for _, issue := range report.Issues {
+		fingerprint, err := baselineFingerprint(rootPath, issue)
+		if err != nil {
+			return nil, err
+		}
+		b[fingerprint]++
+	}
+	return b, nil
+}
+
+// filter returns the issues which are not in the baseline
+func (b baseline) filter(issues []*gosec.Issue, rootPath string) ([]*gosec.Issue, error) {
+	remaining := make(baseline, len(b))
+	for fingerprint, count := range b {
+		remaining[fingerprint] = count
+	}
+	var found []*gosec.Issue
+	for _, issue := range issues {
+		fingerprint, err := baselineFingerprint(rootPath, issue)
+		if err != nil {
+			return nil, err
+		}
+		if remaining[fingerprint] > 0 {
+			remaining[fingerprint]--
+			continue
+		}
+		found = append(found, issue)
+	}
+	return found, nil
+}
+
+// baselinePath returns the slash separated path of a file relative to the
+// root path. The paths read from a baseline file are relative already. A
+// file outside of the root path cannot be matched across checkouts, so it
+// is an error.
+func baselinePath(rootPath, file string) (string, error) {
+	if !filepath.IsAbs(file) {
+		return filepath.ToSlash(file), nil
+	}
+	rel, ok := gosec.GetRelativePath(rootPath, file)
+	if !ok {
+		return "", fmt.Errorf("%s is outside of the project root %s, use -root to set the root of the baseline", file, rootPath)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// baselineFingerprint returns the fingerprint of an issue, with its file
+// placed in the root path
+func baselineFingerprint(rootPath string, issue *gosec.Issue) (string, error) {
+	rel, err := baselinePath(rootPath, issue.File)
+	if err != nil {
+		return "", err
+	}
+	placed := *issue
+	placed.File = filepath.Join(rootPath, filepath.FromSlash(rel))
+	return placed.Fingerprint(rootPath), nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/securego/gosec"
+)
+
+var _ = Describe("Baseline", func() {
+	var (
+		dir          string
+		baselineFile string
+	)
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "gosec_baseline")
+		Expect(err).ShouldNot(HaveOccurred())
+		baselineFile = filepath.Join(dir, "baseline.json")
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	newIssue := func(root, file, code string) *gosec.Issue {
+		return &gosec.Issue{
+			Severity:   gosec.Medium,
+			Confidence: gosec.High,
+			RuleID:     "weak-hash",
+			What:       "Use of weak cryptographic primitive",
+			File:       filepath.Join(root, file),
+			Code:       code,
+			Line:       "10",
+			Function:   "main",
+		}
+	}
+
+	Context("when writing a baseline", func() {
+		It("should store the file paths relative to the root", func() {
+			root := filepath.Join(dir, "checkout")
+			err := writeBaseline(baselineFile, root, []*gosec.Issue{newIssue(root, "pkg/md5.go", "md5.New()")})
+			Expect(err).ShouldNot(HaveOccurred())
+			data, err := ioutil.ReadFile(baselineFile)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(string(data)).Should(ContainSubstring(`"file": "pkg/md5.go"`))
+			Expect(string(data)).ShouldNot(ContainSubstring(root))
+		})
+
+		It("should fail for the issues outside of the root", func() {
+			root := filepath.Join(dir, "checkout")
+			err := writeBaseline(baselineFile, root, []*gosec.Issue{newIssue(dir, "other/md5.go", "md5.New()")})
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).Should(ContainSubstring("outside of the project root"))
+		})
+	})
+
+	Context("when filtering the issues", func() {
+		It("should match the issues of a checkout moved to another directory", func() {
+			oldRoot := filepath.Join(dir, "old")
+			newRoot := filepath.Join(dir, "new")
+			err := writeBaseline(baselineFile, oldRoot, []*gosec.Issue{newIssue(oldRoot, "pkg/md5.go", "md5.New()")})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			b, err := loadBaseline(baselineFile, newRoot)
+			Expect(err).ShouldNot(HaveOccurred())
+			moved := newIssue(newRoot, "pkg/md5.go", "md5.New()")
+			moved.Line = "42"
+			added := newIssue(newRoot, "pkg/md5.go", "md5.Sum(data)")
+			issues, err := b.filter([]*gosec.Issue{moved, added}, newRoot)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(issues).Should(Equal([]*gosec.Issue{added}))
+		})
+
+		It("should match a duplicated issue as many times as it is in the baseline", func() {
+			root := filepath.Join(dir, "checkout")
+			err := writeBaseline(baselineFile, root, []*gosec.Issue{newIssue(root, "md5.go", "md5.New()")})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			b, err := loadBaseline(baselineFile, root)
+			Expect(err).ShouldNot(HaveOccurred())
+			first := newIssue(root, "md5.go", "md5.New()")
+			second := newIssue(root, "md5.go", "md5.New()")
+			second.Line = "20"
+			issues, err := b.filter([]*gosec.Issue{first, second}, root)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(issues).Should(Equal([]*gosec.Issue{second}))
+		})
+
+		It("should read a json report with absolute file paths", func() {
+			root := filepath.Join(dir, "checkout")
+			report := `{"Issues": [{"rule_id": "weak-hash", "file": "` + filepath.ToSlash(filepath.Join(root, "md5.go")) + `", "code": "md5.New()", "line": "3", "function": "main"}]}`
+			err := ioutil.WriteFile(baselineFile, []byte(report), 0644)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			b, err := loadBaseline(baselineFile, root)
+			Expect(err).ShouldNot(HaveOccurred())
+			issues, err := b.filter([]*gosec.Issue{newIssue(root, "md5.go", "md5.New()")}, root)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(issues).Should(BeEmpty())
+		})
+
+		It("should fail for the issues outside of the root", func() {
+			root := filepath.Join(dir, "checkout")
+			b := make(baseline)
+			_, err := b.filter([]*gosec.Issue{newIssue(dir, "md5.go", "md5.New()")}, root)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+})
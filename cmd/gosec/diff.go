@@ -0,0 +1,293 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/build"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/securego/gosec"
+)
+
+// hunkHeader matches the line numbers of the new side of a diff hunk
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// lineRange is a range of lines, both ends included
+type lineRange struct {
+	start, end int
+}
+
+// changes holds the changed lines of the files, keyed by absolute path.
+// A file without ranges is new, all of its lines are changed.
+type changes map[string][]lineRange
+
+// gitChanges runs git diff to find the lines changed since a revision,
+// including the untracked files
+func gitChanges(rev string) (changes, error) {
+	top, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	// resolved like the paths of the issues, e.g. for a checkout under a
+	// symlinked directory
+	root := resolvePath(strings.TrimSpace(string(top)))
+	diff, err := git("-C", root, "diff", "--no-color", "--no-ext-diff", "--no-renames", "--unified=0", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+	c, err := parseDiff(root, diff)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git("-C", root, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(string(untracked), "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			c[filepath.Join(root, name)] = nil
+		}
+	}
+	return c, nil
+}
+
+func git(args ...string) ([]byte, error) {
+	// #nosec cmd-exec -- the revision is a command line argument
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+// parseDiff reads the changed lines of a diff without context lines. The
+// lines around the removed lines are counted as changed, since removing a
+// line, e.g. a validation, may introduce an issue in its neighbours. The
+// diff is made without renames, a renamed file is removed and added.
+func parseDiff(root string, diff []byte) (changes, error) {
+	c := make(changes)
+	var file, removed string
+	scanner := bufio.NewScanner(bytes.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			removed = ""
+			name := strings.TrimRight(strings.TrimPrefix(line, "--- "), "\t")
+			if strings.HasPrefix(name, "a/") {
+				removed = filepath.Join(root, strings.TrimPrefix(name, "a/"))
+			}
+		case strings.HasPrefix(line, "+++ "):
+			file = ""
+			name := strings.TrimRight(strings.TrimPrefix(line, "+++ "), "\t")
+			if strings.HasPrefix(name, "b/") {
+				file = filepath.Join(root, strings.TrimPrefix(name, "b/"))
+				c[file] = []lineRange{}
+			} else if removed != "" {
+				// a deleted file has no lines left, but its package changed
+				c[removed] = []lineRange{}
+			}
+		case strings.HasPrefix(line, "@@ ") && file != "":
+			match := hunkHeader.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("invalid diff hunk: %s", line)
+			}
+			start, _ := strconv.Atoi(match[1])
+			count := 1
+			if match[2] != "" {
+				count, _ = strconv.Atoi(match[2])
+			}
+			if count == 0 {
+				// only removed lines, after the given line
+				c[file] = append(c[file], lineRange{start, start + 1})
+			} else {
+				c[file] = append(c[file], lineRange{start, start + count - 1})
+			}
+		}
+	}
+	return c, scanner.Err()
+}
+
+// contains checks whether the lines of a report, e.g. "12" or "12-14",
+// overlap the changed lines of a file
+func (c changes) contains(file, lines string) bool {
+	ranges, ok := c[resolvePath(file)]
+	if !ok {
+		return false
+	}
+	if ranges == nil {
+		return true
+	}
+	parts := strings.SplitN(lines, "-", 2)
+	start, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	end := start
+	if len(parts) == 2 {
+		if end, err = strconv.Atoi(parts[1]); err != nil {
+			return false
+		}
+	}
+	for _, r := range ranges {
+		if start <= r.end && r.start <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// packages returns the package directories holding changed Go files, and
+// the directories of the packages importing them, directly or not, since
+// the flow of user input into an importing package may go through the
+// changed lines
+func (c changes) packages(dirs []string) []string {
+	changed := make(map[string]bool)
+	for file := range c {
+		if strings.HasSuffix(file, ".go") {
+			changed[filepath.Dir(file)] = true
+		}
+	}
+	deps := newDependencies()
+	var packages []string
+	for _, dir := range dirs {
+		if deps.reaches(resolvePath(dir), changed) {
+			packages = append(packages, dir)
+		}
+	}
+	return packages
+}
+
+// dependencies resolves the local packages imported by the packages. The
+// packages of the standard library and of the module cache are left out,
+// they are not changed in the repository.
+type dependencies struct {
+	modCache string
+	// imports are the imported directories by package directory
+	imports map[string][]string
+	// resolved are the directories of the import paths, by module root
+	resolved map[string]string
+	// changed tells whether a package depends on a changed package
+	changed map[string]bool
+}
+
+func newDependencies() *dependencies {
+	modCache := os.Getenv("GOMODCACHE")
+	if modCache == "" {
+		if gopaths := filepath.SplitList(build.Default.GOPATH); len(gopaths) > 0 {
+			modCache = filepath.Join(gopaths[0], "pkg", "mod")
+		}
+	}
+	return &dependencies{
+		modCache: modCache,
+		imports:  make(map[string][]string),
+		resolved: make(map[string]string),
+		changed:  make(map[string]bool),
+	}
+}
+
+// reaches checks whether the package of a directory is changed or imports a
+// changed package, directly or not
+func (d *dependencies) reaches(dir string, changed map[string]bool) bool {
+	if changed[dir] {
+		return true
+	}
+	if reached, ok := d.changed[dir]; ok {
+		return reached
+	}
+	// an import cycle does not reach the changed packages through itself
+	d.changed[dir] = false
+	for _, imported := range d.importsOf(dir) {
+		if d.reaches(imported, changed) {
+			d.changed[dir] = true
+			break
+		}
+	}
+	return d.changed[dir]
+}
+
+// importsOf returns the directories of the local packages imported by the
+// package of a directory, including its tests
+func (d *dependencies) importsOf(dir string) []string {
+	if imports, ok := d.imports[dir]; ok {
+		return imports
+	}
+	ctx := build.Default
+	ctx.Dir, _ = gosec.GetModuleRoot(dir)
+	bp, err := ctx.ImportDir(dir, build.ImportComment)
+	if err != nil {
+		d.imports[dir] = nil
+		return nil
+	}
+	var imports []string
+	seen := make(map[string]bool)
+	for _, path := range append(append(bp.Imports, bp.TestImports...), bp.XTestImports...) {
+		if seen[path] || path == "C" {
+			continue
+		}
+		seen[path] = true
+		key := ctx.Dir + "\x00" + path
+		importedDir, ok := d.resolved[key]
+		if !ok {
+			if imported, err := ctx.Import(path, dir, build.FindOnly); err == nil && !imported.Goroot &&
+				!strings.HasPrefix(imported.Dir, d.modCache+string(filepath.Separator)) {
+				importedDir = resolvePath(imported.Dir)
+			}
+			d.resolved[key] = importedDir
+		}
+		if importedDir != "" {
+			imports = append(imports, importedDir)
+		}
+	}
+	d.imports[dir] = imports
+	return imports
+}
+
+// filter returns the issues on the changed lines, or whose flow of user
+// input goes through the changed lines
+func (c changes) filter(issues []*gosec.Issue) []*gosec.Issue {
+	var found []*gosec.Issue
+	for _, issue := range issues {
+		changed := c.contains(issue.File, issue.Line)
+		for _, step := range issue.Trace {
+			changed = changed || c.contains(step.File, step.Line)
+		}
+		if changed {
+			found = append(found, issue)
+		}
+	}
+	return found
+}
+
+// resolvePath returns the absolute path of a file with the symbolic links
+// resolved, as reported by git
+func resolvePath(path string) string {
+	if abspath, err := filepath.Abs(path); err == nil {
+		path = abspath
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/securego/gosec"
+)
+
+var _ = Describe("Diff", func() {
+	var root string
+	BeforeEach(func() {
+		var err error
+		root, err = ioutil.TempDir("", "gosec_diff")
+		Expect(err).ShouldNot(HaveOccurred())
+		root = resolvePath(root)
+	})
+	AfterEach(func() {
+		os.RemoveAll(root)
+	})
+
+	Context("when parsing a diff", func() {
+		It("should read the changed lines of the new side", func() {
+			diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3 +3 @@ import "os"
+-	a := 1
++	a := 2
+@@ -10,0 +11,3 @@ func main() {
++	b := 1
++	c := 2
++	d := 3
+`
+			c, err := parseDiff(root, []byte(diff))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(c).Should(Equal(changes{
+				filepath.Join(root, "main.go"): {{3, 3}, {11, 13}},
+			}))
+		})
+
+		It("should count the lines around the removed lines as changed", func() {
+			diff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -5,2 +4,0 @@ func main() {
+-	if !valid(input) {
+-		return
+`
+			c, err := parseDiff(root, []byte(diff))
+			Expect(err).ShouldNot(HaveOccurred())
+			file := filepath.Join(root, "main.go")
+			Expect(c[file]).Should(Equal([]lineRange{{4, 5}}))
+			Expect(c.contains(file, "4")).Should(BeTrue())
+			Expect(c.contains(file, "5")).Should(BeTrue())
+			Expect(c.contains(file, "6")).Should(BeFalse())
+		})
+
+		It("should keep the package of a deleted file", func() {
+			diff := `diff --git a/pkg/validate.go b/pkg/validate.go
+deleted file mode 100644
+--- a/pkg/validate.go
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package pkg
+-
+-func valid(s string) bool { return s != "" }
+`
+			c, err := parseDiff(root, []byte(diff))
+			Expect(err).ShouldNot(HaveOccurred())
+			file := filepath.Join(root, "pkg", "validate.go")
+			Expect(c).Should(HaveKey(file))
+			Expect(c.contains(file, "1")).Should(BeFalse())
+		})
+
+		It("should read a renamed file as removed and added", func() {
+			diff := `diff --git a/old/main.go b/old/main.go
+deleted file mode 100644
+--- a/old/main.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package main
+-func main() {}
+diff --git a/new/main.go b/new/main.go
+new file mode 100644
+--- /dev/null
++++ b/new/main.go
+@@ -0,0 +1,2 @@
++package main
++func main() {}
+`
+			c, err := parseDiff(root, []byte(diff))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(c).Should(HaveKey(filepath.Join(root, "old", "main.go")))
+			Expect(c[filepath.Join(root, "new", "main.go")]).Should(Equal([]lineRange{{1, 2}}))
+		})
+	})
+
+	Context("when reading the changes of a git checkout", func() {
+		It("should match the files of a checkout under a symlinked directory", func() {
+			checkout := filepath.Join(root, "checkout")
+			link := filepath.Join(root, "link")
+			Expect(os.MkdirAll(checkout, 0755)).Should(Succeed())
+			Expect(os.Symlink(checkout, link)).Should(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(checkout, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)).Should(Succeed())
+			for _, args := range [][]string{
+				{"init", "-q"},
+				{"add", "main.go"},
+				{"-c", "user.name=gosec", "-c", "user.email=gosec@example.com", "commit", "-q", "-m", "main"},
+			} {
+				_, err := git(append([]string{"-C", checkout}, args...)...)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			Expect(ioutil.WriteFile(filepath.Join(checkout, "main.go"), []byte("package main\n\nfunc main() { println() }\n"), 0644)).Should(Succeed())
+
+			wd, err := os.Getwd()
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.Chdir(wd)
+			Expect(os.Chdir(link)).Should(Succeed())
+			os.Setenv("PWD", link)
+			defer os.Setenv("PWD", wd)
+
+			c, err := gitChanges("HEAD")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(c.contains(filepath.Join(link, "main.go"), "3")).Should(BeTrue())
+			Expect(c.contains(filepath.Join(link, "main.go"), "1")).Should(BeFalse())
+		})
+	})
+
+	Context("when filtering the issues", func() {
+		It("should keep the issues whose flow goes through the changed lines of another file", func() {
+			c := changes{
+				filepath.Join(root, "input.go"): {{5, 5}},
+			}
+			traced := &gosec.Issue{
+				File: filepath.Join(root, "main.go"),
+				Line: "20",
+				Trace: []*gosec.TraceStep{
+					{File: filepath.Join(root, "input.go"), Line: "4-6"},
+					{File: filepath.Join(root, "main.go"), Line: "20"},
+				},
+			}
+			untraced := &gosec.Issue{
+				File: filepath.Join(root, "main.go"),
+				Line: "30",
+				Trace: []*gosec.TraceStep{
+					{File: filepath.Join(root, "input.go"), Line: "8"},
+				},
+			}
+			changedLine := &gosec.Issue{File: filepath.Join(root, "input.go"), Line: "5"}
+			Expect(c.filter([]*gosec.Issue{traced, untraced, changedLine})).Should(Equal([]*gosec.Issue{traced, changedLine}))
+		})
+	})
+
+	Context("when selecting the packages", func() {
+		write := func(file, content string) {
+			path := filepath.Join(root, file)
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).Should(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(content), 0644)).Should(Succeed())
+		}
+
+		It("should add the packages importing a changed package", func() {
+			write("go.mod", "module example.com/app\n")
+			write("input/input.go", "package input\n\nfunc Read() string { return \"\" }\n")
+			write("handler/handler.go", "package handler\n\nimport \"example.com/app/input\"\n\nfunc Handle() string { return input.Read() }\n")
+			write("main.go", "package main\n\nimport \"example.com/app/handler\"\n\nfunc main() { handler.Handle() }\n")
+			write("other/other.go", "package other\n\nimport \"os\"\n\nfunc Other() string { return os.Getenv(\"A\") }\n")
+
+			c := changes{filepath.Join(root, "input", "input.go"): {{3, 3}}}
+			dirs := []string{root, filepath.Join(root, "handler"), filepath.Join(root, "input"), filepath.Join(root, "other")}
+			Expect(c.packages(dirs)).Should(Equal(dirs[:3]))
+		})
+	})
+})
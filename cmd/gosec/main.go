@@ -20,9 +20,11 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -62,11 +64,14 @@ USAGE:
 )
 
 var (
-	// #nosec flag
+	// ignore the #nosec annotations
 	flagIgnoreNoSec = flag.Bool("nosec", false, "Ignores #nosec comments when set")
 
 	// format output
-	flagFormat = flag.String("fmt", "json", "Set output format. Valid options are: json, yaml, csv, junit-xml, html, or text")
+	flagFormat = flag.String("fmt", "json", "Set output format. Valid options are: json, yaml, csv, junit-xml, sonarqube, sarif, html, or text")
+
+	// project root for relative file paths in the report
+	flagProjectRoot = flag.String("root", "", "Project root used for the relative file paths in the sonarqube and sarif reports and in the baseline (default: current directory)")
 
 	// output file
 	flagOutput = flag.String("out", "", "Set output file for results")
@@ -104,9 +109,45 @@ var (
 	// do not fail
 	flagNoFail = flag.Bool("no-fail", false, "Do not fail the scanning, even if issues were found")
 
+	// number of packages checked in parallel
+	flagConcurrency = flag.Int("concurrency", 0, "Number of packages checked in parallel (default: number of CPUs)")
+
+	// resolve values with the SSA form of the packages
+	flagSSA = flag.Bool("ssa", false, "Build the SSA form of the packages to resolve the values checked by the rules")
+
+	// cache the results of the packages
+	flagCacheDir = flag.String("cache-dir", "", "Directory in which the results of the unchanged packages are cached between the scans")
+
+	// report only the issues which are not in a previous report
+	flagBaseline = flag.String("baseline", "", "Path to a previous json report whose issues are not reported again")
+
+	// create or refresh the baseline
+	flagWriteBaseline = flag.Bool("write-baseline", false, "Write the issues found to the baseline file given with -baseline")
+
+	// report only the issues on the lines changed since a git revision
+	flagDiffBase = flag.String("diff-base", "", "Only scan the packages changed since the given git revision, and report the issues on the changed lines")
+
+	// skip the generated files
+	flagSkipGenerated = flag.Bool("skip-generated", false, "Skip the files with a \"// Code generated ... DO NOT EDIT.\" comment")
+
+	// scan the test files
+	flagTests = flag.Bool("tests", false, "Scan the _test.go files")
+
+	// directories and files which are not scanned
+	flagExcludeDirs = newFileList()
+	flagSkipFiles   = newFileList()
+
+	// print the run information on stderr
+	flagBanner = flag.Bool("banner", false, "Print the start time, collection type and source of the scan on stderr")
+
 	logger *log.Logger
 )
 
+func init() {
+	flag.Var(flagExcludeDirs, "exclude-dir", "Exclude the directories matching a pattern, or holding it in their path, from the scan (can be repeated)")
+	flag.Var(flagSkipFiles, "skip", "Skip the files matching a pattern, or holding it in their path (can be repeated)")
+}
+
 // #nosec
 func usage() {
 
@@ -146,9 +187,39 @@ func loadConfig(configFile string) (gosec.Config, error) {
 	if *flagIgnoreNoSec {
 		config.SetGlobal(gosec.Nosec, "true")
 	}
+	if *flagConcurrency > 0 {
+		config.SetGlobal(gosec.Concurrency, strconv.Itoa(*flagConcurrency))
+	}
+	if *flagSSA {
+		config.SetGlobal(gosec.SSA, "enabled")
+	}
+	if *flagCacheDir != "" {
+		config.SetGlobal(gosec.CacheDir, *flagCacheDir)
+	}
+	if *flagSkipGenerated {
+		config.SetGlobal(gosec.SkipGenerated, "enabled")
+	}
+	if *flagTests {
+		config.SetGlobal(gosec.Tests, "enabled")
+	}
+	// the patterns of the configuration are added to the ones of the flags
+	addPatterns(flagExcludeDirs, config, gosec.ExcludeDirs)
+	addPatterns(flagSkipFiles, config, gosec.Skip)
 	return config, nil
 }
 
+// addPatterns adds the comma separated patterns of a global option to a
+// file list
+func addPatterns(list *fileList, config gosec.Config, option gosec.GlobalOption) {
+	value, err := config.GetGlobal(option)
+	if err != nil {
+		return
+	}
+	for _, pattern := range strings.Split(value, ",") {
+		list.Set(strings.TrimSpace(pattern))
+	}
+}
+
 func loadRules(include, exclude string) rules.RuleList {
 	var filters []rules.RuleFilter
 	if include != "" {
@@ -169,19 +240,19 @@ func loadRules(include, exclude string) rules.RuleList {
 	return rules.Generate(filters...)
 }
 
-func saveOutput(filename, format string, issues []*gosec.Issue, metrics *gosec.Metrics, errors map[string][]gosec.Error) error {
+func saveOutput(filename, format, rootPath string, run *output.RunInfo, issues []*gosec.Issue, metrics *gosec.Metrics, errors map[string][]gosec.Error, suppressions []*gosec.Suppression) error {
 	if filename != "" {
 		outfile, err := os.Create(filename)
 		if err != nil {
 			return err
 		}
 		defer outfile.Close()
-		err = output.CreateReport(outfile, format, issues, metrics, errors)
+		err = output.CreateReport(outfile, format, rootPath, run, issues, metrics, errors, suppressions)
 		if err != nil {
 			return err
 		}
 	} else {
-		err := output.CreateReport(os.Stdout, format, issues, metrics, errors)
+		err := output.CreateReport(os.Stdout, format, rootPath, run, issues, metrics, errors, suppressions)
 		if err != nil {
 			return err
 		}
@@ -225,6 +296,64 @@ func cleanPaths(paths []string) []string {
 	return clean
 }
 
+// listModulePackages runs the go command in the root directory of a Go
+// module to expand a package pattern, such as ./..., into the package
+// directories. The go command resolves the pattern using the module's
+// go.mod file and ignores the vendor and testdata directories.
+func listModulePackages(moduleRoot, pattern string, buildTags []string) ([]string, error) {
+	dir := strings.TrimSuffix(pattern, "/...")
+	if _, err := os.Stat(dir); err == nil {
+		// the go command runs in the module root, so directories are
+		// passed with an absolute path
+		abspath, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		pattern = strings.Replace(pattern, dir, abspath, 1)
+	}
+	// #nosec cmd-exec -- the pattern is a command line argument
+	cmd := exec.Command("go", "list", "-e", "-tags="+strings.Join(buildTags, ","), "-f", "{{.Dir}}", pattern)
+	cmd.Dir = moduleRoot
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the packages matching %s in module %s: %v", pattern, moduleRoot, err)
+	}
+	var dirs []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+	return dirs, nil
+}
+
+// resolvePackages expands the package patterns given on the command line
+// into package directories. Patterns within a Go module are expanded by
+// the go command, the remaining patterns are resolved on the $GOPATH.
+func resolvePackages(patterns []string, buildTags []string) ([]string, error) {
+	var dirs, gopathPatterns []string
+	for _, pattern := range patterns {
+		moduleRoot, ok := gosec.GetModuleRoot(strings.TrimSuffix(pattern, "/..."))
+		if !ok {
+			gopathPatterns = append(gopathPatterns, pattern)
+			continue
+		}
+		moduleDirs, err := listModulePackages(moduleRoot, pattern, buildTags)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, moduleDirs...)
+	}
+	if len(gopathPatterns) > 0 {
+		gopaths := gosec.Gopath()
+		for _, pkg := range gotool.ImportPaths(cleanPaths(gopathPatterns)) {
+			dirs = append(dirs, resolvePackage(pkg, gopaths))
+		}
+	}
+	return dirs, nil
+}
+
 func resolvePackage(pkg string, searchPaths []string) string {
 	for _, basedir := range searchPaths {
 		dir := filepath.Join(basedir, "src", pkg)
@@ -251,14 +380,7 @@ func convertToScore(severity string) (gosec.Score, error) {
 
 func main() {
 
-	start_time := time.Now()
-	fmt.Fprintf(os.Stdout, "Created_time: " + start_time.Format(time.RFC3339) + "\n")
-
-	collection_type := "SAST"
-	fmt.Fprintf(os.Stdout, "collection_type: " + collection_type + "\n")
-
-	source := "GoSec" 
-	fmt.Fprintf(os.Stdout, "Source: " + source + "\n")
+	startTime := time.Now()
 
 	// Setup usage description
 	flag.Usage = usage
@@ -266,6 +388,12 @@ func main() {
 	// Parse command line arguments
 	flag.Parse()
 
+	// The run information is in the report, the banner is only printed on
+	// stderr to keep the report on stdout machine-readable
+	if *flagBanner {
+		fmt.Fprintf(os.Stderr, "Created_time: %s\ncollection_type: %s\nSource: %s\n", startTime.Format(time.RFC3339), output.CollectionType, output.Source)
+	}
+
 	// Ensure at least one file was specified
 	if flag.NArg() == 0 {
 		fmt.Fprintf(os.Stderr, "\nError: FILE [FILE...] or './...' expected\n") // #nosec
@@ -295,6 +423,10 @@ func main() {
 		logger.Fatal(err)
 	}
 
+	if *flagWriteBaseline && *flagBaseline == "" {
+		logger.Fatal("-write-baseline requires the -baseline file")
+	}
+
 	// Load config
 	config, err := loadConfig(*flagConfig)
 	if err != nil {
@@ -310,13 +442,25 @@ func main() {
 	// Create the analyzer
 	analyzer := gosec.NewAnalyzer(config, logger, *flagNoTaintAnalysis)
 	analyzer.LoadRules(ruleDefinitions.Builders())
+	analyzer.AddRuleIDs(rules.Generate().IDs()...)
+	analyzer.SkipFiles(func(filename string) bool {
+		return flagSkipFiles.Contains(filename) || flagExcludeDirs.Contains(filepath.Dir(filename))
+	})
+
+	var buildTags []string
+	if *flagBuildTags != "" {
+		buildTags = strings.Split(*flagBuildTags, ",")
+	}
 
 	vendor := regexp.MustCompile(`[\\/]vendor([\\/]|$)`)
 
-	var packages []string
 	// Iterate over packages on the import paths
-	gopaths := gosec.Gopath()
-	for _, pkg := range gotool.ImportPaths(cleanPaths(flag.Args())) {
+	dirs, err := resolvePackages(flag.Args(), buildTags)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	var packages []string
+	for _, pkg := range dirs {
 
 		// Skip vendor directory
 		if !*flagScanVendor {
@@ -324,25 +468,71 @@ func main() {
 				continue
 			}
 		}
-		packages = append(packages, resolvePackage(pkg, gopaths))
+		if flagExcludeDirs.Contains(pkg) {
+			continue
+		}
+		packages = append(packages, pkg)
 	}
 
-	var buildTags []string
-	if *flagBuildTags != "" {
-		buildTags = strings.Split(*flagBuildTags, ",")
+	// Limit the scan to the packages holding changed files
+	var changed changes
+	if *flagDiffBase != "" {
+		if changed, err = gitChanges(*flagDiffBase); err != nil {
+			logger.Fatal(err)
+		}
+		packages = changed.packages(packages)
+		logger.Printf("Scanning %d packages changed since %s", len(packages), *flagDiffBase)
 	}
+
 	if err := analyzer.Process(buildTags, packages...); err != nil {
 		logger.Fatal(err)
 	}
 
 	// Collect the results
-	issues, metrics, errors := analyzer.Report()
+	issues, metrics, errors, suppressions := analyzer.Report()
 
 	// Sort the issue by severity
 	if *flagSortIssues {
 		sortIssues(issues)
 	}
 
+	// Leave out the issues outside of the changed lines
+	if changed != nil {
+		issues = changed.filter(issues)
+		metrics.NumFound = len(issues)
+	}
+
+	rootPath := *flagProjectRoot
+	if rootPath == "" {
+		if rootPath, err = os.Getwd(); err != nil {
+			logger.Fatal(err)
+		}
+	}
+
+	// Leave out the issues of the baseline, after refreshing it if asked
+	if *flagBaseline != "" {
+		baselineRoot, err := filepath.Abs(rootPath)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if *flagWriteBaseline {
+			if err := writeBaseline(*flagBaseline, baselineRoot, issues); err != nil {
+				logger.Fatal(err)
+			}
+			logger.Printf("Baseline written to %s with %d issues", *flagBaseline, len(issues))
+		}
+		baseline, err := loadBaseline(*flagBaseline, baselineRoot)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		issues, err = baseline.filter(issues, baselineRoot)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		metrics.NumBaseline = metrics.NumFound - len(issues)
+		metrics.NumFound = len(issues)
+	}
+
 	issuesFound := false
 	for _, issue := range issues {
 		if issue.Severity >= failSeverity {
@@ -357,7 +547,8 @@ func main() {
 	}
 
 	// Create output report
-	if err := saveOutput(*flagOutput, *flagFormat, issues, metrics, errors); err != nil {
+	run := output.NewRunInfo(startTime, time.Now(), version())
+	if err := saveOutput(*flagOutput, *flagFormat, rootPath, run, issues, metrics, errors, suppressions); err != nil {
 		logger.Fatal(err)
 	}
 
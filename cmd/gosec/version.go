@@ -8,3 +8,12 @@ var GitTag string
 
 // BuildDate is the date when the build was created
 var BuildDate string
+
+// version returns the version reported in the run information, the git
+// tag if the build version is not set
+func version() string {
+	if Version == "" {
+		return GitTag
+	}
+	return Version
+}
@@ -12,6 +12,9 @@ const (
 	// Globals are applicable to all rules and used for general
 	// configuration settings for gosec.
 	Globals = "global"
+	// Rules is the section holding the settings of the rules, keyed by
+	// rule ID
+	Rules = "rules"
 )
 
 // GlobalOption defines the name of the global options
@@ -22,8 +25,42 @@ const (
 	Nosec GlobalOption = "nosec"
 	// Audit global option which indicates that gosec runs in audit mode
 	Audit GlobalOption = "audit"
+	// NosecJustification global option which requires each #nosec directive
+	// to give a justification, e.g. "#nosec -- input is validated"
+	NosecJustification GlobalOption = "nosec-justification"
+	// Concurrency global option for the number of packages checked in
+	// parallel, which defaults to the number of CPUs
+	Concurrency GlobalOption = "concurrency"
+	// SSA global option which enables the rules matching the SSA form of
+	// the scanned packages
+	SSA GlobalOption = "ssa"
+	// CacheDir global option for the directory in which the results of
+	// the packages are cached between the scans
+	CacheDir GlobalOption = "cache-dir"
+	// ExcludeDirs global option for the comma separated patterns of the
+	// directories which are not scanned
+	ExcludeDirs GlobalOption = "exclude-dir"
+	// Skip global option for the comma separated patterns of the files
+	// which are not scanned
+	Skip GlobalOption = "skip"
+	// SkipGenerated global option which leaves out the files marked with a
+	// "// Code generated ... DO NOT EDIT." comment
+	SkipGenerated GlobalOption = "skip-generated"
+	// Tests global option which scans the _test.go files, which are
+	// skipped by default
+	Tests GlobalOption = "tests"
 )
 
+// RuleSettings overrides the defaults of a rule. A disabled rule is not
+// run, and its issues, e.g. the ones of a taint sink, are not reported.
+// The fields which are not set keep the values given by the rule.
+type RuleSettings struct {
+	Enabled    *bool  `json:"enabled"`
+	Severity   *Score `json:"severity"`
+	Confidence *Score `json:"confidence"`
+	Message    string `json:"message"`
+}
+
 // Config is used to provide configuration and customization to each of the rules.
 type Config map[string]interface{}
 
@@ -47,9 +84,24 @@ func (c Config) ReadFrom(r io.Reader) (int64, error) {
 	if err = json.Unmarshal(data, &c); err != nil {
 		return int64(len(data)), err
 	}
+	c.convertGlobals()
 	return int64(len(data)), nil
 }
 
+// convertGlobals converts the global section decoded from JSON into the
+// map type expected by the global option accessors
+func (c Config) convertGlobals() {
+	globals, ok := c[Globals].(map[string]interface{})
+	if !ok {
+		return
+	}
+	settings := make(map[GlobalOption]string)
+	for option, value := range globals {
+		settings[GlobalOption(option)] = fmt.Sprintf("%v", value)
+	}
+	c[Globals] = settings
+}
+
 // WriteTo implements the io.WriteTo interface. This should
 // be used to save or print out the configuration information.
 func (c Config) WriteTo(w io.Writer) (int64, error) {
@@ -74,6 +126,30 @@ func (c Config) Set(section string, value interface{}) {
 	c[section] = value
 }
 
+// GetRuleSettings returns the settings of the rules section, keyed by rule
+// ID with the legacy aliases resolved
+func (c Config) GetRuleSettings() (map[string]*RuleSettings, error) {
+	section, ok := c[Rules]
+	if !ok {
+		return nil, nil
+	}
+	data, err := json.Marshal(section)
+	if err != nil {
+		return nil, err
+	}
+	var declared map[string]*RuleSettings
+	if err := json.Unmarshal(data, &declared); err != nil {
+		return nil, err
+	}
+	settings := make(map[string]*RuleSettings, len(declared))
+	for id, setting := range declared {
+		if setting != nil {
+			settings[ResolveRuleID(id)] = setting
+		}
+	}
+	return settings, nil
+}
+
 // GetGlobal returns value associated with global configuration option
 func (c Config) GetGlobal(option GlobalOption) (string, error) {
 	if globals, ok := c[Globals]; ok {
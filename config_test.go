@@ -24,6 +24,15 @@ var _ = Describe("Configuration", func() {
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 
+		It("should be possible to load global options from a file", func() {
+			json := `{"global": {"nosec": "enabled"}}`
+			_, err := configuration.ReadFrom(bytes.NewBufferString(json))
+			Expect(err).ShouldNot(HaveOccurred())
+			enabled, err := configuration.IsGlobalEnabled(gosec.Nosec)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(enabled).Should(BeTrue())
+		})
+
 		It("should return an error if configuration file is invalid", func() {
 			var err error
 			invalidBuffer := bytes.NewBuffer([]byte{0xc0, 0xff, 0xee})
@@ -57,7 +66,7 @@ var _ = Describe("Configuration", func() {
 			nbytes, err := configuration.WriteTo(buffer)
 			Expect(int(nbytes)).ShouldNot(BeZero())
 			Expect(err).ShouldNot(HaveOccurred())
-			Expect(buffer.String()).Should(Equal(`{"hardcreds":{"mode":"strict"},"global":{}}`))
+			Expect(buffer.String()).Should(Equal(`{"global":{},"hardcreds":{"mode":"strict"}}`))
 
 		})
 	})
@@ -75,6 +84,27 @@ var _ = Describe("Configuration", func() {
 			Expect(retrieved).Should(HaveKeyWithValue("ciphers", "AES256-GCM"))
 			Expect(retrieved).ShouldNot(HaveKey("foobar"))
 		})
+
+		It("should read the settings of the rules section", func() {
+			json := `{"rules": {"unsafe-block": {"severity": "HIGH"}, "G104": {"enabled": false, "message": "Unchecked error"}}}`
+			_, err := configuration.ReadFrom(bytes.NewBufferString(json))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			settings, err := configuration.GetRuleSettings()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(settings).Should(HaveLen(2))
+			Expect(*settings["unsafe-block"].Severity).Should(Equal(gosec.High))
+			Expect(settings["unsafe-block"].Enabled).Should(BeNil())
+			Expect(settings).Should(HaveKey("error-check"))
+			Expect(*settings["error-check"].Enabled).Should(BeFalse())
+			Expect(settings["error-check"].Message).Should(Equal("Unchecked error"))
+		})
+
+		It("should return an error for an invalid rule setting", func() {
+			configuration.Set(gosec.Rules, map[string]interface{}{"unsafe-block": map[string]interface{}{"severity": "URGENT"}})
+			_, err := configuration.GetRuleSettings()
+			Expect(err).Should(HaveOccurred())
+		})
 	})
 
 	Context("when using global configuration options", func() {
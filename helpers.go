@@ -15,6 +15,7 @@
 package gosec
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"go/ast"
@@ -23,6 +24,7 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -290,6 +292,63 @@ func GetPkgRelativePath(path string) (string, error) {
 	return "", errors.New("no project relative path found")
 }
 
+// GetRelativePath returns the path of a file relative to a root directory,
+// and false when the file is outside of the root directory
+func GetRelativePath(root string, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path, false
+	}
+	return rel, true
+}
+
+// GetModuleRoot returns the root directory of the Go module containing
+// the given path, which is the closest parent directory with a go.mod file
+func GetModuleRoot(path string) (string, bool) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	if st, err := os.Stat(dir); err == nil && !st.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		if st, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !st.IsDir() {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// generatedHeader matches the comment marking the generated files
+var generatedHeader = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// IsGeneratedFile checks whether a Go file has a "// Code generated ...
+// DO NOT EDIT." comment before its package clause
+func IsGeneratedFile(filename string) (bool, error) {
+	// #nosec taint-file-path -- the file is being scanned
+	file, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if generatedHeader.MatchString(line) {
+			return true, nil
+		}
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+	}
+	return false, scanner.Err()
+}
+
 // GetPkgAbsPath returns the Go package absolute path derived from
 // the given path
 func GetPkgAbsPath(pkgPath string) (string, error) {
@@ -1,14 +1,91 @@
 package gosec_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo"
-	//. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega"
+	"github.com/securego/gosec"
 )
 
 var _ = Describe("Helpers", func() {
-	Context("todo", func() {
-		It("should fail", func() {
-			Skip("Not implemented")
+	Context("when finding the root of a Go module", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "gosec_module")
+			Expect(err).ShouldNot(HaveOccurred())
+			dir, err = filepath.EvalSymlinks(dir)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("should find the closest directory with a go.mod file", func() {
+			nested := filepath.Join(dir, "cmd", "app")
+			Expect(os.MkdirAll(nested, 0755)).Should(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644)).Should(Succeed())
+
+			root, ok := gosec.GetModuleRoot(nested)
+			Expect(ok).Should(BeTrue())
+			Expect(root).Should(Equal(dir))
+		})
+
+		It("should not find a module root for a directory outside of a module", func() {
+			_, ok := gosec.GetModuleRoot(dir)
+			Expect(ok).Should(BeFalse())
+		})
+	})
+
+	Context("when getting the path of a file relative to a root", func() {
+		root := filepath.FromSlash("/src/project")
+
+		It("should return the path of a file in the root", func() {
+			rel, ok := gosec.GetRelativePath(root, filepath.Join(root, "..data", "main.go"))
+			Expect(ok).Should(BeTrue())
+			Expect(rel).Should(Equal(filepath.Join("..data", "main.go")))
+		})
+
+		It("should reject the files outside of the root", func() {
+			_, ok := gosec.GetRelativePath(root, filepath.FromSlash("/src/other/main.go"))
+			Expect(ok).Should(BeFalse())
+			_, ok = gosec.GetRelativePath(root, filepath.FromSlash("/src"))
+			Expect(ok).Should(BeFalse())
+		})
+	})
+
+	Context("when checking whether a file is generated", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "gosec_generated")
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("should find the generated code comment before the package clause", func() {
+			filename := filepath.Join(dir, "api.pb.go")
+			source := "// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: api.proto\n\npackage api\n"
+			Expect(ioutil.WriteFile(filename, []byte(source), 0644)).Should(Succeed())
+
+			generated, err := gosec.IsGeneratedFile(filename)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(generated).Should(BeTrue())
+		})
+
+		It("should not consider the comments after the package clause", func() {
+			filename := filepath.Join(dir, "api.go")
+			source := "package api\n\n// Code generated by protoc-gen-go. DO NOT EDIT.\n"
+			Expect(ioutil.WriteFile(filename, []byte(source), 0644)).Should(Succeed())
+
+			generated, err := gosec.IsGeneratedFile(filename)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(generated).Should(BeFalse())
 		})
 	})
 })
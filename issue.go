@@ -15,11 +15,18 @@
 package gosec
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"go/ast"
+	"go/token"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
 )
 
 // Score type used by severity and confidence values
@@ -43,6 +50,22 @@ type Issue struct {
 	File       string `json:"file"`       // File name we found it in
 	Code       string `json:"code"`       // Impacted code line
 	Line       string `json:"line"`       // Line number in file
+
+	// Function is the name of the function enclosing the issue, with the
+	// receiver type for the methods, e.g. "Server.Handle"
+	Function string `json:"function,omitempty"`
+
+	// Trace holds the flow of the data from its source to the sink for the
+	// issues reported by the taint analysis
+	Trace []*TraceStep `json:"trace,omitempty"`
+}
+
+// TraceStep is a location through which tainted data flows
+type TraceStep struct {
+	What string `json:"details"` // What happens to the data at this step
+	File string `json:"file"`    // File name of the step
+	Code string `json:"code"`    // Code of the step
+	Line string `json:"line"`    // Line number in file
 }
 
 // MetaData is embedded in all gosec rules. The Severity, Confidence and What message
@@ -54,11 +77,37 @@ type MetaData struct {
 	What       string
 }
 
+// GetMetaData returns the metadata of a rule, which describes the most
+// severe issues it reports
+func (m MetaData) GetMetaData() MetaData {
+	return m
+}
+
 // MarshalJSON is used convert a Score object into a JSON representation
 func (c Score) MarshalJSON() ([]byte, error) {
 	return json.Marshal(c.String())
 }
 
+// UnmarshalJSON is used to convert the JSON representation of a Score, as
+// produced by MarshalJSON, back into a Score object
+func (c *Score) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch strings.ToUpper(value) {
+	case "HIGH":
+		*c = High
+	case "MEDIUM":
+		*c = Medium
+	case "LOW":
+		*c = Low
+	default:
+		return fmt.Errorf("invalid score: %s", value)
+	}
+	return nil
+}
+
 // String converts a Score into a string
 func (c Score) String() string {
 	switch c {
@@ -87,18 +136,20 @@ func codeSnippet(file *os.File, start int64, end int64, n ast.Node) (string, err
 	return string(buf), nil
 }
 
-// NewIssue creates a new Issue
-func NewIssue(ctx *Context, node ast.Node, ruleID, desc string, severity Score, confidence Score) *Issue {
-	var code string
-	fobj := ctx.FileSet.File(node.Pos())
-	name := fobj.Name()
-
+// lineRange returns the line number of a node, or the range of line
+// numbers if the node spans several lines
+func lineRange(fobj *token.File, node ast.Node) string {
 	start, end := fobj.Line(node.Pos()), fobj.Line(node.End())
-	line := strconv.Itoa(start)
 	if start != end {
-		line = fmt.Sprintf("%d-%d", start, end)
+		return fmt.Sprintf("%d-%d", start, end)
 	}
+	return strconv.Itoa(start)
+}
 
+// nodeCode returns the code of a node read from its file, or the reason
+// why it could not be read
+func nodeCode(fobj *token.File, node ast.Node) string {
+	var code string
 	// #nosec
 	if file, err := os.Open(fobj.Name()); err == nil {
 		defer file.Close()
@@ -109,14 +160,83 @@ func NewIssue(ctx *Context, node ast.Node, ruleID, desc string, severity Score,
 			code = err.Error()
 		}
 	}
+	return code
+}
 
+// NewIssue creates a new Issue
+func NewIssue(ctx *Context, node ast.Node, ruleID, desc string, severity Score, confidence Score) *Issue {
+	fobj := ctx.FileSet.File(node.Pos())
 	return &Issue{
-		File:       name,
-		Line:       line,
+		File:       fobj.Name(),
+		Line:       lineRange(fobj, node),
 		RuleID:     ruleID,
 		What:       desc,
 		Confidence: confidence,
 		Severity:   severity,
-		Code:       code,
+		Code:       nodeCode(fobj, node),
+		Function:   enclosingFunction(ctx, node),
+	}
+}
+
+// enclosingFunction returns the name of the function declaration enclosing
+// a node, "Type.Method" for methods, or an empty string outside functions
+func enclosingFunction(ctx *Context, node ast.Node) string {
+	files := ctx.PkgFiles
+	if ctx.Root != nil {
+		files = append([]*ast.File{ctx.Root}, files...)
+	}
+	for _, file := range files {
+		if node.Pos() < file.Pos() || node.End() > file.End() {
+			continue
+		}
+		path, _ := astutil.PathEnclosingInterval(file, node.Pos(), node.End())
+		for _, n := range path {
+			if decl, ok := n.(*ast.FuncDecl); ok {
+				if decl.Recv == nil || len(decl.Recv.List) == 0 {
+					return decl.Name.Name
+				}
+				return receiverName(decl.Recv.List[0].Type) + "." + decl.Name.Name
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// receiverName returns the name of the type of a method receiver
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.ParenExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// Fingerprint identifies an issue independently of its line number, so
+// that it is matched across the changes shifting the code around. It
+// covers the rule ID, the file path relative to the root path, the code
+// with its whitespace collapsed and the enclosing function.
+func (i *Issue) Fingerprint(rootPath string) string {
+	file := i.File
+	if rel, ok := GetRelativePath(rootPath, file); ok {
+		file = rel
+	}
+	code := strings.Join(strings.Fields(i.Code), " ")
+	sum := sha256.Sum256([]byte(strings.Join([]string{i.RuleID, filepath.ToSlash(file), i.Function, code}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewTraceStep creates a new TraceStep
+func NewTraceStep(ctx *Context, node ast.Node, desc string) *TraceStep {
+	fobj := ctx.FileSet.File(node.Pos())
+	return &TraceStep{
+		What: desc,
+		File: fobj.Name(),
+		Code: nodeCode(fobj, node),
+		Line: lineRange(fobj, node),
 	}
 }
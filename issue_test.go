@@ -1,6 +1,7 @@
 package gosec_test
 
 import (
+	"encoding/json"
 	"go/ast"
 
 	. "github.com/onsi/ginkgo"
@@ -90,10 +91,59 @@ var _ = Describe("Issue", func() {
 			Skip("Not implemented")
 		})
 
+		It("should read a score from its JSON representation", func() {
+			var score gosec.Score
+			Expect(json.Unmarshal([]byte(`"high"`), &score)).Should(Succeed())
+			Expect(score).Should(Equal(gosec.High))
+			Expect(json.Unmarshal([]byte(`"urgent"`), &score)).ShouldNot(Succeed())
+		})
+
 		It("should maintain the provided confidence score", func() {
 			Skip("Not implemented")
 		})
 
+		It("should record the function enclosing the issue", func() {
+			var target *ast.BasicLit
+			source := `package main
+			type server struct{}
+			func (s *server) handle() {
+				println("bar")
+			}
+			func main(){
+			}
+			`
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("foo.go", source)
+			ctx := pkg.CreateContext("foo.go")
+			v := testutils.NewMockVisitor()
+			v.Callback = func(n ast.Node, ctx *gosec.Context) bool {
+				if node, ok := n.(*ast.BasicLit); ok {
+					target = node
+					return false
+				}
+				return true
+			}
+			v.Context = ctx
+			ast.Walk(v, ctx.Root)
+			Expect(target).ShouldNot(BeNil())
+
+			issue := gosec.NewIssue(ctx, target, "TEST", "", gosec.High, gosec.High)
+			Expect(issue.Function).Should(Equal("server.handle"))
+		})
+
+		It("should fingerprint an issue independently of its line and whitespace", func() {
+			issue := &gosec.Issue{RuleID: "insecure-lib", File: "/src/app/md5.go", Line: "12", Code: "h := md5.New()", Function: "checksum"}
+			moved := &gosec.Issue{RuleID: "insecure-lib", File: "/src/app/md5.go", Line: "20", Code: "h :=  md5.New()", Function: "checksum"}
+			Expect(moved.Fingerprint("/src")).Should(Equal(issue.Fingerprint("/src")))
+
+			checkout := &gosec.Issue{RuleID: "insecure-lib", File: "/build/app/md5.go", Line: "12", Code: "h := md5.New()", Function: "checksum"}
+			Expect(checkout.Fingerprint("/build")).Should(Equal(issue.Fingerprint("/src")))
+
+			other := &gosec.Issue{RuleID: "insecure-lib", File: "/src/app/md5.go", Line: "12", Code: "h := md5.New()", Function: "sign"}
+			Expect(other.Fingerprint("/src")).ShouldNot(Equal(issue.Fingerprint("/src")))
+		})
+
 	})
 
 })
@@ -0,0 +1,133 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// TaintAnalysisID is the rule ID used for issues reported by the taint
+	// analysis pass. It can be listed in a #nosec annotation like any other
+	// rule ID.
+	TaintAnalysisID = "TaintAnalysis"
+
+	// NosecAll is the key used in Metrics.NosecByRule for #nosec annotations
+	// that do not list any rule and therefore suppress every rule.
+	NosecAll = "*"
+
+	// NosecJustificationID is the rule ID used for issues reported on #nosec
+	// annotations without a justification, when one is required by the
+	// NosecJustification global option.
+	NosecJustificationID = "nosec-justification"
+
+	nosecDirective              = "#nosec"
+	nosecJustificationSeparator = "--"
+)
+
+// ruleAliases maps the legacy numeric rule identifiers onto the rule IDs
+// used by this scanner, so that existing annotations such as `#nosec G401`
+// keep working.
+var ruleAliases = map[string]string{
+	"G101": "hardcreds",
+	"G102": "bind-interfaces",
+	"G103": "unsafe-block",
+	"G104": "error-check",
+	"G105": "math-audit",
+	"G106": "insecure-ssh-key",
+	"G107": "taint-http",
+	"G201": "sql-format-string",
+	"G202": "sql-string-concat",
+	"G203": "unescaped-html-data",
+	"G204": "cmd-exec",
+	"G301": "dir-perm",
+	"G302": "poor-chmod",
+	"G303": "predict-path",
+	"G304": "taint-file-path",
+	"G305": "file-traverse",
+	"G401": "insecure-lib",
+	"G402": "bad-tls",
+	"G403": "min-key-rsa",
+	"G404": "insecure-rand",
+	"G501": "blacklist-md5",
+	"G502": "blacklist-des",
+	"G503": "blacklist-rc4",
+	"G504": "blacklist-http-cgi",
+	"G505": "blacklist-sha1",
+}
+
+var nosecRuleSeparator = regexp.MustCompile(`[\s,]+`)
+
+// ResolveRuleID returns the rule ID for the supplied identifier, resolving
+// legacy aliases such as G401. Unknown identifiers are returned unchanged.
+func ResolveRuleID(id string) string {
+	if rule, ok := ruleAliases[strings.ToUpper(id)]; ok {
+		return rule
+	}
+	return id
+}
+
+// Suppression records a #nosec annotation found while scanning, so that
+// it can be audited in the report.
+type Suppression struct {
+	File          string   `json:"file"`          // File name the annotation was found in
+	Line          string   `json:"line"`          // Line numbers of the annotated code
+	RuleIDs       []string `json:"rule_ids"`      // Suppressed rules, empty if all rules are suppressed
+	Justification string   `json:"justification"` // Reason given after the "--" separator
+}
+
+// parseNosec looks for a #nosec directive at the start of a line of the
+// text of a comment. The directive may be followed by a list of rule IDs or
+// aliases separated by commas or whitespace and by a justification after a
+// "--" separator, e.g. `#nosec sql-string-concat, taint-http -- input is an
+// enum`. The list of rule IDs ends at the first word which isRule does not
+// accept, and any other text is taken as the justification, e.g.
+// `#nosec this is safe`. The returned IDs have their aliases resolved; an
+// empty list means that all rules are suppressed.
+func parseNosec(text string, isRule func(string) bool) ([]string, string, bool) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, nosecDirective) {
+			continue
+		}
+		directive := line[len(nosecDirective):]
+		if directive != "" && !nosecRuleSeparator.MatchString(directive[:1]) && !strings.HasPrefix(directive, nosecJustificationSeparator) {
+			continue
+		}
+		justification := ""
+		if sep := strings.Index(directive, nosecJustificationSeparator); sep >= 0 {
+			justification = strings.TrimSpace(directive[sep+len(nosecJustificationSeparator):])
+			directive = directive[:sep]
+		}
+		var ids []string
+		rest := strings.Trim(directive, " \t,")
+		for rest != "" {
+			word, next := rest, ""
+			if loc := nosecRuleSeparator.FindStringIndex(rest); loc != nil {
+				word, next = rest[:loc[0]], rest[loc[1]:]
+			}
+			id := ResolveRuleID(word)
+			if !isRule(id) {
+				break
+			}
+			ids = append(ids, id)
+			rest = next
+		}
+		if justification == "" {
+			justification = rest
+		}
+		return ids, justification, true
+	}
+	return nil, "", false
+}
@@ -18,9 +18,14 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	htmlTemplate "html/template"
 	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
 	plainTemplate "text/template"
+	"time"
 
 	"github.com/securego/gosec"
 	"gopkg.in/yaml.v2"
@@ -41,6 +46,12 @@ const (
 
 	// ReportJUnitXML set the output format to junit xml
 	ReportJUnitXML // JUnit XML format
+
+	// ReportSonarqube set the output format to sonarqube external issues
+	ReportSonarqube // Sonarqube format
+
+	// ReportSARIF set the output format to SARIF 2.1.0
+	ReportSARIF // SARIF format
 )
 
 var text = `Results:
@@ -53,29 +64,76 @@ Golang errors in file: [{{ $filePath }}]:
 {{ range $index, $issue := .Issues }}
 [{{ $issue.File }}:{{ $issue.Line }}] - {{ $issue.RuleID }}: {{ $issue.What }} (Confidence: {{ $issue.Confidence}}, Severity: {{ $issue.Severity }})
   > {{ $issue.Code }}
+{{ range $step := $issue.Trace }}
+    [{{ $step.File }}:{{ $step.Line }}] {{ $step.What }}
+      > {{ $step.Code }}{{ end }}
 
 {{ end }}
-Summary:
+{{ if .Suppressions }}Suppressions:
+{{ range $index, $suppression := .Suppressions }}
+[{{ $suppression.File }}:{{ $suppression.Line }}] - #nosec {{ if $suppression.RuleIDs }}{{ join $suppression.RuleIDs ", " }}{{ else }}(all rules){{ end }}{{ if $suppression.Justification }} -- {{ $suppression.Justification }}{{ end }}
+{{ end }}
+{{ end }}Summary:
    Files: {{.Stats.NumFiles}}
    Lines: {{.Stats.NumLines}}
    Nosec: {{.Stats.NumNosec}}
   Issues: {{.Stats.NumFound}}
-
+{{ if .Stats.NumBaseline }}Baseline: {{.Stats.NumBaseline}}
+{{ end }}{{ if or .Stats.CacheHits .Stats.CacheMisses }}   Cache: {{.Stats.CacheHits}} hits, {{.Stats.CacheMisses}} misses
+{{ end }}
 `
 
 type reportInfo struct {
-	Errors map[string][]gosec.Error `json:"Golang errors"`
-	Issues []*gosec.Issue
-	Stats  *gosec.Metrics
+	Run          *RunInfo                 `json:"run,omitempty" yaml:"run,omitempty"`
+	Errors       map[string][]gosec.Error `json:"Golang errors"`
+	Issues       []*gosec.Issue
+	Stats        *gosec.Metrics
+	Suppressions []*gosec.Suppression
+}
+
+const (
+	// CollectionType is the kind of analysis reported by gosec
+	CollectionType = "SAST"
+	// Source is the name of the tool reported in the run information
+	Source = "GoSec"
+)
+
+// RunInfo describes the scan which produced a report. It is reported in
+// the json, yaml and sarif formats.
+type RunInfo struct {
+	StartTime      string  `json:"start_time" yaml:"start_time"`
+	EndTime        string  `json:"end_time" yaml:"end_time"`
+	Duration       float64 `json:"duration" yaml:"duration"` // seconds
+	Version        string  `json:"version" yaml:"version"`
+	CollectionType string  `json:"collection_type" yaml:"collection_type"`
+	Source         string  `json:"source" yaml:"source"`
+}
+
+// NewRunInfo creates the information of a scan run between the given times
+// by the given version of gosec
+func NewRunInfo(start, end time.Time, version string) *RunInfo {
+	return &RunInfo{
+		StartTime:      start.Format(time.RFC3339),
+		EndTime:        end.Format(time.RFC3339),
+		Duration:       end.Sub(start).Seconds(),
+		Version:        version,
+		CollectionType: CollectionType,
+		Source:         Source,
+	}
 }
 
 // CreateReport generates a report based for the supplied issues and metrics given
-// the specified format. The formats currently accepted are: json, csv, html and text.
-func CreateReport(w io.Writer, format string, issues []*gosec.Issue, metrics *gosec.Metrics, errors map[string][]gosec.Error) error {
+// the specified format. The formats currently accepted are: json, yaml, csv,
+// junit-xml, sonarqube, sarif, html and text. File paths in the sonarqube and
+// sarif reports are made relative to rootPath. The run information, which may
+// be nil, is reported in the json, yaml and sarif formats.
+func CreateReport(w io.Writer, format string, rootPath string, run *RunInfo, issues []*gosec.Issue, metrics *gosec.Metrics, errors map[string][]gosec.Error, suppressions []*gosec.Suppression) error {
 	data := &reportInfo{
-		Errors: errors,
-		Issues: issues,
-		Stats:  metrics,
+		Run:          run,
+		Errors:       errors,
+		Issues:       issues,
+		Stats:        metrics,
+		Suppressions: suppressions,
 	}
 	var err error
 	switch format {
@@ -87,6 +145,10 @@ func CreateReport(w io.Writer, format string, issues []*gosec.Issue, metrics *go
 		err = reportCSV(w, data)
 	case "junit-xml":
 		err = reportJUnitXML(w, data)
+	case "sonarqube":
+		err = reportSonarqube(rootPath, w, data)
+	case "sarif":
+		err = reportSARIF(rootPath, w, data)
 	case "html":
 		err = reportFromHTMLTemplate(w, html, data)
 	case "text":
@@ -97,6 +159,40 @@ func CreateReport(w io.Writer, format string, issues []*gosec.Issue, metrics *go
 	return err
 }
 
+// getRelativePath returns the path of the file relative to the project
+// root, or the unchanged path if the file is outside of the project root
+func getRelativePath(rootPath string, file string) string {
+	if rootPath == "" {
+		return file
+	}
+	root, err := filepath.Abs(rootPath)
+	if err != nil {
+		return file
+	}
+	rel, ok := gosec.GetRelativePath(root, file)
+	if !ok {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+// getLineRange converts the line of an issue, or a range of lines like
+// "12-15", into the first and last line numbers
+func getLineRange(line string) (int, int, error) {
+	lines := strings.SplitN(line, "-", 2)
+	start, err := strconv.Atoi(lines[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid line %q: %v", line, err)
+	}
+	end := start
+	if len(lines) > 1 {
+		if end, err = strconv.Atoi(lines[1]); err != nil {
+			return 0, 0, fmt.Errorf("invalid line %q: %v", line, err)
+		}
+	}
+	return start, end, nil
+}
+
 func reportJSON(w io.Writer, data *reportInfo) error {
 	raw, err := json.MarshalIndent(data, "", "\t")
 	if err != nil {
@@ -110,6 +206,32 @@ func reportJSON(w io.Writer, data *reportInfo) error {
 	return err
 }
 
+func reportSonarqube(rootPath string, w io.Writer, data *reportInfo) error {
+	si, err := convertToSonarIssues(rootPath, data)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(si, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(raw)
+	return err
+}
+
+func reportSARIF(rootPath string, w io.Writer, data *reportInfo) error {
+	sr, err := convertToSarifReport(rootPath, data)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(sr, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(raw)
+	return err
+}
+
 func reportYAML(w io.Writer, data *reportInfo) error {
 	raw, err := yaml.Marshal(data)
 	if err != nil {
@@ -158,7 +280,7 @@ func reportJUnitXML(w io.Writer, data *reportInfo) error {
 }
 
 func reportFromPlaintextTemplate(w io.Writer, reportTemplate string, data *reportInfo) error {
-	t, e := plainTemplate.New("gosec").Parse(reportTemplate)
+	t, e := plainTemplate.New("gosec").Funcs(plainTemplate.FuncMap{"join": strings.Join}).Parse(reportTemplate)
 	if e != nil {
 		return e
 	}
@@ -0,0 +1,163 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/securego/gosec"
+)
+
+// report is a small set of results: an issue, an issue with the flow of the
+// taint analysis, an issue outside of the project root, golang errors with
+// and without a column, and a suppression
+type report struct {
+	root         string
+	run          *RunInfo
+	issues       []*gosec.Issue
+	metrics      *gosec.Metrics
+	errors       map[string][]gosec.Error
+	suppressions []*gosec.Suppression
+}
+
+func newReport() *report {
+	start := time.Date(2020, 3, 4, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	return &report{
+		root: "/project",
+		run:  NewRunInfo(start, start.Add(1500*time.Millisecond), "1.2.3"),
+		issues: []*gosec.Issue{{
+			Severity:   gosec.Medium,
+			Confidence: gosec.High,
+			RuleID:     "insecure-lib",
+			What:       "Use of weak cryptographic primitive",
+			File:       "/project/pkg/md5.go",
+			Code:       "h := md5.New()",
+			Line:       "12",
+		}, {
+			Severity:   gosec.High,
+			Confidence: gosec.High,
+			RuleID:     "taint-sql",
+			What:       "SQL query built from user input",
+			File:       "/project/main.go",
+			Code:       "db.Query(query)",
+			Line:       "20-21",
+			Trace: []*gosec.TraceStep{
+				{What: "user input from os.Args", File: "/project/input.go", Code: "name := os.Args[1]", Line: "5"},
+				{What: "passed to db.Query", File: "/project/main.go", Code: "db.Query(query)", Line: "20-21"},
+			},
+		}, {
+			Severity:   gosec.Low,
+			Confidence: gosec.Medium,
+			RuleID:     "error-check",
+			What:       "Errors unhandled.",
+			File:       "/other/lib.go",
+			Code:       "f.Close()",
+			Line:       "7",
+		}},
+		metrics: &gosec.Metrics{NumFiles: 4, NumLines: 120, NumNosec: 1, NumFound: 3},
+		errors: map[string][]gosec.Error{
+			"/project/broken.go": {
+				{Line: 3, Column: 1, Err: "expected ';', found 'EOF'"},
+				{Line: 9, Column: 0, Err: "undeclared name: x"},
+			},
+		},
+		suppressions: []*gosec.Suppression{{
+			File:          "/project/pkg/md5.go",
+			Line:          "14",
+			RuleIDs:       []string{"insecure-lib"},
+			Justification: "checksum only",
+		}},
+	}
+}
+
+// render creates a report of the results in the given format
+func (r *report) render(format string) []byte {
+	buffer := &bytes.Buffer{}
+	err := CreateReport(buffer, format, r.root, r.run, r.issues, r.metrics, r.errors, r.suppressions)
+	Expect(err).ShouldNot(HaveOccurred())
+	return buffer.Bytes()
+}
+
+var _ = Describe("Formatter", func() {
+	Context("when reading the lines of an issue", func() {
+		It("should read a single line", func() {
+			start, end, err := getLineRange("12")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect([]int{start, end}).Should(Equal([]int{12, 12}))
+		})
+
+		It("should read a range of lines", func() {
+			start, end, err := getLineRange("12-15")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect([]int{start, end}).Should(Equal([]int{12, 15}))
+		})
+
+		It("should return an error for an invalid line", func() {
+			_, _, err := getLineRange("12-")
+			Expect(err).Should(HaveOccurred())
+			_, _, err = getLineRange("")
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("when making the paths relative", func() {
+		It("should make the paths of the files under the root relative", func() {
+			Expect(getRelativePath("/project", "/project/pkg/md5.go")).Should(Equal("pkg/md5.go"))
+		})
+
+		It("should keep the paths of the files outside of the root", func() {
+			Expect(getRelativePath("/project", "/other/lib.go")).Should(Equal("/other/lib.go"))
+			Expect(getRelativePath("/project", "/project-other/lib.go")).Should(Equal("/project-other/lib.go"))
+			Expect(getRelativePath("", "/project/main.go")).Should(Equal("/project/main.go"))
+		})
+	})
+
+	Context("when reporting the run information", func() {
+		It("should describe the run between the given times", func() {
+			r := newReport()
+			Expect(*r.run).Should(Equal(RunInfo{
+				StartTime:      "2020-03-04T10:00:00+01:00",
+				EndTime:        "2020-03-04T10:00:01+01:00",
+				Duration:       1.5,
+				Version:        "1.2.3",
+				CollectionType: "SAST",
+				Source:         "GoSec",
+			}))
+		})
+
+		It("should report the run information, the flows and the suppressions in json", func() {
+			var decoded struct {
+				Run          *RunInfo
+				Issues       []*gosec.Issue
+				Suppressions []*gosec.Suppression
+			}
+			Expect(json.Unmarshal(newReport().render("json"), &decoded)).Should(Succeed())
+			Expect(decoded.Run).Should(Equal(newReport().run))
+			Expect(decoded.Issues).Should(HaveLen(3))
+			Expect(decoded.Issues[1].Trace).Should(Equal(newReport().issues[1].Trace))
+			Expect(decoded.Suppressions).Should(Equal(newReport().suppressions))
+		})
+
+		It("should leave out the run information when there is none", func() {
+			r := newReport()
+			r.run = nil
+			var decoded map[string]interface{}
+			Expect(json.Unmarshal(r.render("json"), &decoded)).Should(Succeed())
+			Expect(decoded).ShouldNot(HaveKey("run"))
+			Expect(decoded).Should(HaveKey("Issues"))
+		})
+
+		It("should report the run information in yaml", func() {
+			Expect(string(newReport().render("yaml"))).Should(ContainSubstring("run:\n  start_time: 2020-03-04T10:00:00+01:00\n"))
+			Expect(string(newReport().render("yaml"))).Should(ContainSubstring("  version: 1.2.3\n"))
+		})
+
+		It("should list the flows and the suppressions in the text report", func() {
+			text := string(newReport().render("text"))
+			Expect(text).Should(ContainSubstring("    [/project/input.go:5] user input from os.Args\n      > name := os.Args[1]"))
+			Expect(text).Should(ContainSubstring("[/project/pkg/md5.go:14] - #nosec insecure-lib -- checksum only"))
+		})
+	})
+})
@@ -0,0 +1,337 @@
+package output
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/securego/gosec"
+	"github.com/securego/gosec/rules"
+)
+
+const (
+	sarifSchema         = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	sarifVersion        = "2.1.0"
+	sarifToolName       = "gosec"
+	sarifInformationURI = "https://github.com/securego/gosec"
+	sarifSourceRoot     = "%SRCROOT%"
+)
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine"`
+	EndLine     int           `json:"endLine,omitempty"`
+	StartColumn int           `json:"startColumn,omitempty"`
+	Snippet     *sarifMessage `json:"snippet,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifThreadFlowLocation struct {
+	Location *sarifLocation `json:"location"`
+}
+
+type sarifThreadFlow struct {
+	Locations []*sarifThreadFlowLocation `json:"locations"`
+}
+
+type sarifCodeFlow struct {
+	ThreadFlows []*sarifThreadFlow `json:"threadFlows"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version,omitempty"`
+	InformationURI string       `json:"informationUri"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifProperties struct {
+	Severity   string `json:"severity"`
+	Confidence string `json:"confidence"`
+}
+
+type sarifResult struct {
+	RuleID     string           `json:"ruleId"`
+	RuleIndex  int              `json:"ruleIndex"`
+	Level      string           `json:"level"`
+	Message    sarifMessage     `json:"message"`
+	Locations  []*sarifLocation `json:"locations"`
+	CodeFlows  []*sarifCodeFlow `json:"codeFlows,omitempty"`
+	Properties sarifProperties  `json:"properties"`
+}
+
+type sarifNotification struct {
+	Level     string           `json:"level"`
+	Message   sarifMessage     `json:"message"`
+	Locations []*sarifLocation `json:"locations"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                 `json:"executionSuccessful"`
+	StartTimeUTC               string               `json:"startTimeUtc,omitempty"`
+	EndTimeUTC                 string               `json:"endTimeUtc,omitempty"`
+	ToolExecutionNotifications []*sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifRunProperties struct {
+	CollectionType string `json:"collectionType"`
+	Source         string `json:"source"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool           `json:"tool"`
+	Results     []*sarifResult      `json:"results"`
+	Invocations []*sarifInvocation  `json:"invocations"`
+	Properties  *sarifRunProperties `json:"properties,omitempty"`
+}
+
+type sarifReport struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+// getSarifLevel maps the severity of an issue onto a sarif level
+func getSarifLevel(s gosec.Score) string {
+	switch s {
+	case gosec.High:
+		return "error"
+	case gosec.Medium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// getSarifArtifactLocation returns the location of a file relative to the
+// source root, or its absolute URI if it is outside of the project root
+func getSarifArtifactLocation(rootPath string, file string) sarifArtifactLocation {
+	path := getRelativePath(rootPath, file)
+	if filepath.IsAbs(path) {
+		return sarifArtifactLocation{URI: "file://" + filepath.ToSlash(path)}
+	}
+	return sarifArtifactLocation{URI: path, URIBaseID: sarifSourceRoot}
+}
+
+// sarifRuleInfo is the description of a rule with its default severity
+type sarifRuleInfo struct {
+	id          string
+	description string
+	severity    gosec.Score
+}
+
+// buildSarifRules describes every rule known to gosec, including the rules
+// which are not registered in the rule list. The default level of a rule is
+// the severity from its metadata.
+func buildSarifRules() ([]*sarifRule, map[string]int) {
+	infos := []sarifRuleInfo{
+		{gosec.TaintAnalysisID, "Variable tainted with user input and used before validation", gosec.Medium},
+		{gosec.NosecJustificationID, "#nosec annotation without a justification", gosec.Low},
+	}
+	for _, def := range rules.Generate() {
+		info := sarifRuleInfo{id: def.ID, description: def.Description}
+		rule, _ := def.Create(def.ID, gosec.NewConfig())
+		if meta, ok := rule.(interface{ GetMetaData() gosec.MetaData }); ok {
+			info.severity = meta.GetMetaData().Severity
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].id < infos[j].id
+	})
+
+	sarifRules := make([]*sarifRule, 0, len(infos))
+	index := make(map[string]int)
+	for _, info := range infos {
+		index[info.id] = len(sarifRules)
+		sarifRules = append(sarifRules, &sarifRule{
+			ID:                   info.id,
+			Name:                 info.id,
+			ShortDescription:     sarifMessage{Text: info.description},
+			DefaultConfiguration: sarifConfiguration{Level: getSarifLevel(info.severity)},
+		})
+	}
+	return sarifRules, index
+}
+
+func buildSarifResult(rootPath string, issue *gosec.Issue, ruleIndex int) (*sarifResult, error) {
+	startLine, endLine, err := getLineRange(issue.Line)
+	if err != nil {
+		return nil, err
+	}
+	codeFlows, err := buildSarifCodeFlows(rootPath, issue.Trace)
+	if err != nil {
+		return nil, err
+	}
+	return &sarifResult{
+		RuleID:    issue.RuleID,
+		RuleIndex: ruleIndex,
+		Level:     getSarifLevel(issue.Severity),
+		Message:   sarifMessage{Text: issue.What},
+		Locations: []*sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: getSarifArtifactLocation(rootPath, issue.File),
+				Region: sarifRegion{
+					StartLine: startLine,
+					EndLine:   endLine,
+					Snippet:   &sarifMessage{Text: issue.Code},
+				},
+			},
+		}},
+		CodeFlows: codeFlows,
+		Properties: sarifProperties{
+			Severity:   issue.Severity.String(),
+			Confidence: issue.Confidence.String(),
+		},
+	}, nil
+}
+
+// buildSarifCodeFlows converts the trace of a taint analysis issue into a
+// code flow going from the source to the sink
+func buildSarifCodeFlows(rootPath string, trace []*gosec.TraceStep) ([]*sarifCodeFlow, error) {
+	if len(trace) == 0 {
+		return nil, nil
+	}
+	threadFlow := &sarifThreadFlow{}
+	for _, step := range trace {
+		startLine, endLine, err := getLineRange(step.Line)
+		if err != nil {
+			return nil, err
+		}
+		threadFlow.Locations = append(threadFlow.Locations, &sarifThreadFlowLocation{
+			Location: &sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: getSarifArtifactLocation(rootPath, step.File),
+					Region: sarifRegion{
+						StartLine: startLine,
+						EndLine:   endLine,
+						Snippet:   &sarifMessage{Text: step.Code},
+					},
+				},
+				Message: &sarifMessage{Text: step.What},
+			},
+		})
+	}
+	return []*sarifCodeFlow{{ThreadFlows: []*sarifThreadFlow{threadFlow}}}, nil
+}
+
+// buildSarifInvocation reports the golang errors as tool notifications,
+// sorted by file to keep the report stable
+func buildSarifInvocation(rootPath string, errors map[string][]gosec.Error) *sarifInvocation {
+	files := make([]string, 0, len(errors))
+	for file := range errors {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	invocation := &sarifInvocation{ExecutionSuccessful: true}
+	for _, file := range files {
+		for _, e := range errors[file] {
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, &sarifNotification{
+				Level:   "error",
+				Message: sarifMessage{Text: e.Err},
+				Locations: []*sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: getSarifArtifactLocation(rootPath, file),
+						Region: sarifRegion{
+							StartLine:   e.Line,
+							StartColumn: e.Column,
+						},
+					},
+				}},
+			})
+		}
+	}
+	return invocation
+}
+
+func convertToSarifReport(rootPath string, data *reportInfo) (*sarifReport, error) {
+	sarifRules, ruleIndex := buildSarifRules()
+
+	results := make([]*sarifResult, 0, len(data.Issues))
+	for _, issue := range data.Issues {
+		index, ok := ruleIndex[issue.RuleID]
+		if !ok {
+			// rules which are not known in advance are described by their first issue
+			index = len(sarifRules)
+			ruleIndex[issue.RuleID] = index
+			sarifRules = append(sarifRules, &sarifRule{
+				ID:                   issue.RuleID,
+				Name:                 issue.RuleID,
+				ShortDescription:     sarifMessage{Text: issue.What},
+				DefaultConfiguration: sarifConfiguration{Level: getSarifLevel(issue.Severity)},
+			})
+		}
+		result, err := buildSarifResult(rootPath, issue, index)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	run := &sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           sarifToolName,
+				InformationURI: sarifInformationURI,
+				Rules:          sarifRules,
+			},
+		},
+		Results:     results,
+		Invocations: []*sarifInvocation{buildSarifInvocation(rootPath, data.Errors)},
+	}
+	if data.Run != nil {
+		run.Tool.Driver.Version = data.Run.Version
+		run.Invocations[0].StartTimeUTC = sarifTime(data.Run.StartTime)
+		run.Invocations[0].EndTimeUTC = sarifTime(data.Run.EndTime)
+		run.Properties = &sarifRunProperties{CollectionType: data.Run.CollectionType, Source: data.Run.Source}
+	}
+
+	return &sarifReport{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []*sarifRun{run},
+	}, nil
+}
+
+// sarifTime converts a time of the run information to UTC, as required
+// by SARIF
+func sarifTime(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SARIF", func() {
+	var run *sarifRun
+	BeforeEach(func() {
+		var decoded sarifReport
+		Expect(json.Unmarshal(newReport().render("sarif"), &decoded)).Should(Succeed())
+		Expect(decoded.Schema).Should(Equal(sarifSchema))
+		Expect(decoded.Version).Should(Equal("2.1.0"))
+		Expect(decoded.Runs).Should(HaveLen(1))
+		run = decoded.Runs[0]
+	})
+
+	It("should describe the rules with their default level", func() {
+		driver := run.Tool.Driver
+		Expect(driver.Name).Should(Equal("gosec"))
+		Expect(driver.Version).Should(Equal("1.2.3"))
+		levels := make(map[string]string)
+		for _, rule := range driver.Rules {
+			levels[rule.ID] = rule.DefaultConfiguration.Level
+		}
+		Expect(levels).Should(HaveKeyWithValue("insecure-lib", "warning"))
+		Expect(levels).Should(HaveKeyWithValue("TaintAnalysis", "warning"))
+		Expect(levels).Should(HaveKeyWithValue("nosec-justification", "note"))
+	})
+
+	It("should point the results to their rules", func() {
+		Expect(run.Results).Should(HaveLen(3))
+		for _, result := range run.Results {
+			Expect(run.Tool.Driver.Rules[result.RuleIndex].ID).Should(Equal(result.RuleID))
+		}
+		// a rule which is not known in advance is described by its issue
+		taint := run.Tool.Driver.Rules[run.Results[1].RuleIndex]
+		Expect(taint.ShortDescription.Text).Should(Equal("SQL query built from user input"))
+		Expect(taint.DefaultConfiguration.Level).Should(Equal("error"))
+	})
+
+	It("should report the location of the results relative to the source root", func() {
+		result := run.Results[0]
+		Expect(result.Level).Should(Equal("warning"))
+		Expect(result.Message.Text).Should(Equal("Use of weak cryptographic primitive"))
+		Expect(result.Properties).Should(Equal(sarifProperties{Severity: "MEDIUM", Confidence: "HIGH"}))
+		Expect(result.Locations).Should(HaveLen(1))
+		Expect(result.Locations[0].PhysicalLocation).Should(Equal(sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "pkg/md5.go", URIBaseID: "%SRCROOT%"},
+			Region:           sarifRegion{StartLine: 12, EndLine: 12, Snippet: &sarifMessage{Text: "h := md5.New()"}},
+		}))
+		Expect(result.CodeFlows).Should(BeEmpty())
+	})
+
+	It("should report the flow of a taint analysis issue as a code flow", func() {
+		result := run.Results[1]
+		Expect(result.Locations[0].PhysicalLocation.Region).Should(Equal(sarifRegion{StartLine: 20, EndLine: 21, Snippet: &sarifMessage{Text: "db.Query(query)"}}))
+		Expect(result.CodeFlows).Should(HaveLen(1))
+		Expect(result.CodeFlows[0].ThreadFlows).Should(HaveLen(1))
+		locations := result.CodeFlows[0].ThreadFlows[0].Locations
+		Expect(locations).Should(HaveLen(2))
+		Expect(*locations[0].Location).Should(Equal(sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: "input.go", URIBaseID: "%SRCROOT%"},
+				Region:           sarifRegion{StartLine: 5, EndLine: 5, Snippet: &sarifMessage{Text: "name := os.Args[1]"}},
+			},
+			Message: &sarifMessage{Text: "user input from os.Args"},
+		}))
+		Expect(locations[1].Location.Message.Text).Should(Equal("passed to db.Query"))
+	})
+
+	It("should use an absolute URI for a file outside of the root", func() {
+		Expect(run.Results[2].Locations[0].PhysicalLocation.ArtifactLocation).Should(Equal(sarifArtifactLocation{URI: "file:///other/lib.go"}))
+	})
+
+	It("should report the run and the golang errors in the invocation", func() {
+		Expect(run.Invocations).Should(HaveLen(1))
+		invocation := run.Invocations[0]
+		Expect(invocation.ExecutionSuccessful).Should(BeTrue())
+		Expect(invocation.StartTimeUTC).Should(Equal("2020-03-04T09:00:00Z"))
+		Expect(invocation.EndTimeUTC).Should(Equal("2020-03-04T09:00:01Z"))
+		Expect(invocation.ToolExecutionNotifications).Should(HaveLen(2))
+		notification := invocation.ToolExecutionNotifications[0]
+		Expect(notification.Level).Should(Equal("error"))
+		Expect(notification.Message.Text).Should(Equal("expected ';', found 'EOF'"))
+		Expect(notification.Locations[0].PhysicalLocation).Should(Equal(sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "broken.go", URIBaseID: "%SRCROOT%"},
+			Region:           sarifRegion{StartLine: 3, StartColumn: 1},
+		}))
+		Expect(run.Properties).Should(Equal(&sarifRunProperties{CollectionType: "SAST", Source: "GoSec"}))
+	})
+
+	It("should leave out the run properties without run information", func() {
+		r := newReport()
+		r.run = nil
+		var decoded map[string]interface{}
+		Expect(json.Unmarshal(r.render("sarif"), &decoded)).Should(Succeed())
+		runs := decoded["runs"].([]interface{})
+		Expect(runs[0]).ShouldNot(HaveKey("properties"))
+		Expect(runs[0].(map[string]interface{})["invocations"].([]interface{})[0]).ShouldNot(HaveKey("startTimeUtc"))
+	})
+})
@@ -1,10 +1,23 @@
 package output
 
+import (
+	"sort"
+
+	"github.com/securego/gosec"
+)
+
+const (
+	sonarEngineID      = "gosec"
+	sonarBuildEngineID = "gosec-build"
+	sonarBuildRuleID   = "build-error"
+	sonarEffortMinutes = 5
+)
+
 type textRange struct {
-	StartLine   int `json:"startLine"`
-	EndLine     int `json:"endLine"`
-	StartColumn int `json:"startColumn,omitempty"`
-	EtartColumn int `json:"endColumn,omitempty"`
+	StartLine   int  `json:"startLine"`
+	EndLine     int  `json:"endLine"`
+	StartColumn *int `json:"startColumn,omitempty"`
+	EndColumn   *int `json:"endColumn,omitempty"`
 }
 type location struct {
 	Message   string    `json:"message"`
@@ -38,3 +51,62 @@ func getSonarSeverity(s string) string {
 		return "INFO"
 	}
 }
+
+func convertToSonarIssues(rootPath string, data *reportInfo) (*sonarIssues, error) {
+	si := &sonarIssues{SonarIssues: []sonarIssue{}}
+	for _, issue := range data.Issues {
+		startLine, endLine, err := getLineRange(issue.Line)
+		if err != nil {
+			return nil, err
+		}
+		si.SonarIssues = append(si.SonarIssues, sonarIssue{
+			EngineID: sonarEngineID,
+			RuleID:   issue.RuleID,
+			PrimaryLocation: location{
+				Message:   issue.What,
+				FilePath:  getRelativePath(rootPath, issue.File),
+				TextRange: textRange{StartLine: startLine, EndLine: endLine},
+			},
+			Type:          "VULNERABILITY",
+			Severity:      getSonarSeverity(issue.Severity.String()),
+			EffortMinutes: sonarEffortMinutes,
+		})
+	}
+
+	// The golang errors are reported by a separate engine, sorted by file
+	// to keep the report stable
+	files := make([]string, 0, len(data.Errors))
+	for file := range data.Errors {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		for _, e := range data.Errors[file] {
+			si.SonarIssues = append(si.SonarIssues, sonarErrorIssue(rootPath, file, e))
+		}
+	}
+	return si, nil
+}
+
+func sonarErrorIssue(rootPath string, file string, e gosec.Error) sonarIssue {
+	textRange := textRange{StartLine: e.Line, EndLine: e.Line}
+	if e.Column > 0 {
+		// sonarqube columns are zero based, the columns are set together
+		// as the first column is 0
+		startColumn, endColumn := e.Column-1, e.Column
+		textRange.StartColumn = &startColumn
+		textRange.EndColumn = &endColumn
+	}
+	return sonarIssue{
+		EngineID: sonarBuildEngineID,
+		RuleID:   sonarBuildRuleID,
+		PrimaryLocation: location{
+			Message:   e.Err,
+			FilePath:  getRelativePath(rootPath, file),
+			TextRange: textRange,
+		},
+		Type:          "BUG",
+		Severity:      "MAJOR",
+		EffortMinutes: sonarEffortMinutes,
+	}
+}
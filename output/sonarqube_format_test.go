@@ -0,0 +1,64 @@
+package output
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sonarqube", func() {
+	var issues []map[string]interface{}
+	BeforeEach(func() {
+		var decoded struct {
+			Issues []map[string]interface{} `json:"issues"`
+		}
+		Expect(json.Unmarshal(newReport().render("sonarqube"), &decoded)).Should(Succeed())
+		issues = decoded.Issues
+	})
+
+	It("should report the issues with their paths relative to the root", func() {
+		Expect(issues).Should(HaveLen(5))
+		Expect(issues[0]).Should(Equal(map[string]interface{}{
+			"engineId": "gosec",
+			"ruleId":   "insecure-lib",
+			"primaryLocation": map[string]interface{}{
+				"message":   "Use of weak cryptographic primitive",
+				"filePath":  "pkg/md5.go",
+				"textRange": map[string]interface{}{"startLine": 12.0, "endLine": 12.0},
+			},
+			"type":          "VULNERABILITY",
+			"severity":      "MAJOR",
+			"effortMinutes": 5.0,
+		}))
+	})
+
+	It("should map a range of lines and the severities", func() {
+		Expect(issues[1]["primaryLocation"]).Should(HaveKeyWithValue("textRange", map[string]interface{}{"startLine": 20.0, "endLine": 21.0}))
+		Expect(issues[1]["severity"]).Should(Equal("BLOCKER"))
+		Expect(issues[2]["severity"]).Should(Equal("MINOR"))
+	})
+
+	It("should keep the absolute path of a file outside of the root", func() {
+		Expect(issues[2]["primaryLocation"]).Should(HaveKeyWithValue("filePath", "/other/lib.go"))
+	})
+
+	It("should report the golang errors with zero based columns", func() {
+		Expect(issues[3]).Should(Equal(map[string]interface{}{
+			"engineId": "gosec-build",
+			"ruleId":   "build-error",
+			"primaryLocation": map[string]interface{}{
+				"message":   "expected ';', found 'EOF'",
+				"filePath":  "broken.go",
+				"textRange": map[string]interface{}{"startLine": 3.0, "endLine": 3.0, "startColumn": 0.0, "endColumn": 1.0},
+			},
+			"type":          "BUG",
+			"severity":      "MAJOR",
+			"effortMinutes": 5.0,
+		}))
+	})
+
+	It("should leave out the columns of a golang error without a column", func() {
+		Expect(issues[4]["primaryLocation"]).Should(HaveKeyWithValue("textRange", map[string]interface{}{"startLine": 9.0, "endLine": 9.0}))
+	})
+})
@@ -69,8 +69,37 @@ const html = `
       }
     });
     
+    var Trace = React.createClass({
+      render: function() {
+        var steps = this.props.data.map(function(step) {
+          return (
+            <li>
+              <strong className="break-word">
+                { step.file } (line { step.line })
+              </strong>
+              : { step.details }
+              <pre>
+                <code className="golang hljs">
+                  { step.code }
+                </code>
+              </pre>
+            </li>
+          );
+        });
+        return (
+          <details>
+            <summary>Trace ({ this.props.data.length } steps)</summary>
+            <ol>
+              { steps }
+            </ol>
+          </details>
+        );
+      }
+    });
+    
     var Issue = React.createClass({
       render: function() {
+        var trace = this.props.data.trace ? (<Trace data={ this.props.data.trace } />) : null;
         return (
           <div className="issue box">
             <div className="is-pulled-right">
@@ -91,6 +120,7 @@ const html = `
                 </code>
               </pre>
             </figure>
+            { trace }
           </div>
         );
       }
@@ -167,6 +197,42 @@ const html = `
       }
     });
     
+    var Suppressions = React.createClass({
+      render: function() {
+        if (!this.props.data.Suppressions || this.props.data.Suppressions.length === 0) {
+          return null;
+        }
+        var suppressions = this.props.data.Suppressions
+          .map(function(suppression) {
+            var rules = suppression.rule_ids ? suppression.rule_ids.join(", ") : "all rules";
+            return (
+              <tr>
+                <td className="break-word">{ suppression.file } (line { suppression.line })</td>
+                <td>{ rules }</td>
+                <td>{ suppression.justification }</td>
+              </tr>
+            );
+          });
+        return (
+          <div className="box">
+            <strong>Suppressions</strong>
+            <table className="table">
+              <thead>
+                <tr>
+                  <th>Location</th>
+                  <th>Rules</th>
+                  <th>Justification</th>
+                </tr>
+              </thead>
+              <tbody>
+                { suppressions }
+              </tbody>
+            </table>
+          </div>
+        );
+      }
+    });
+    
     var LevelSelector = React.createClass({
       handleChange: function(level) {
         return function(e) {
@@ -380,6 +446,7 @@ const html = `
                   confidence={ this.state.confidence }
                   issueType={ this.state.issueType }
                 />
+                <Suppressions data={ this.props.data } />
               </div>
             </div>
           </div>
@@ -2,6 +2,8 @@ package rules
 
 import (
 	"go/ast"
+	"go/constant"
+	"go/token"
 	"go/types"
 
 	"github.com/securego/gosec"
@@ -9,33 +11,374 @@ import (
 
 type archive struct {
 	gosec.MetaData
-	calls   gosec.CallList
-	argType string
+	calls    gosec.CallList // calls using a path taken from an archive entry
+	joins    gosec.CallList // calls building a path from an archive entry
+	cleans   gosec.CallList // calls keeping the path of an archive entry unsafe
+	argTypes map[string]bool
 }
 
 func (a *archive) ID() string {
 	return a.MetaData.ID
 }
 
-// Match inspects AST nodes to determine if the filepath.Joins uses any argument derived from type zip.File
-func (a *archive) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
-	if node := a.calls.ContainsCallExpr(n, c, false); node != nil {
-		for _, arg := range node.Args {
-			var argType types.Type
-			if selector, ok := arg.(*ast.SelectorExpr); ok {
-				argType = c.Info.TypeOf(selector.X)
-			} else if ident, ok := arg.(*ast.Ident); ok {
-				if ident.Obj != nil && ident.Obj.Kind == ast.Var {
-					decl := ident.Obj.Decl
-					if assign, ok := decl.(*ast.AssignStmt); ok {
-						if selector, ok := assign.Rhs[0].(*ast.SelectorExpr); ok {
-							argType = c.Info.TypeOf(selector.X)
-						}
+// entryName returns the selector of an archive entry field, e.g. file.Name or
+// header.Linkname, from which the argument is derived
+func (a *archive) entryName(arg ast.Expr, c *gosec.Context) *ast.SelectorExpr {
+	switch expr := arg.(type) {
+	case *ast.ParenExpr:
+		return a.entryName(expr.X, c)
+	case *ast.SelectorExpr:
+		if argType := c.Info.TypeOf(expr.X); argType != nil && a.argTypes[argType.String()] {
+			return expr
+		}
+	case *ast.CallExpr:
+		if a.cleans.ContainsCallExpr(expr, c, false) != nil && len(expr.Args) > 0 {
+			return a.entryName(expr.Args[0], c)
+		}
+	case *ast.Ident:
+		if expr.Obj != nil && expr.Obj.Kind == ast.Var {
+			if assign, ok := expr.Obj.Decl.(*ast.AssignStmt); ok {
+				for i, lhs := range assign.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok && ident.Name == expr.Name && i < len(assign.Rhs) {
+						return a.entryName(assign.Rhs[i], c)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// enclosingFunc returns the body of the innermost function holding a node
+func enclosingFunc(n ast.Node, file *ast.File) *ast.BlockStmt {
+	var body *ast.BlockStmt
+	ast.Inspect(file, func(node ast.Node) bool {
+		if node == nil || node.Pos() > n.Pos() || node.End() < n.End() {
+			return false
+		}
+		switch fn := node.(type) {
+		case *ast.FuncDecl:
+			body = fn.Body
+		case *ast.FuncLit:
+			body = fn.Body
+		}
+		return true
+	})
+	return body
+}
+
+// refersTo checks whether an expression is a variable, possibly cleaned
+// with filepath.Clean
+func refersTo(expr ast.Expr, obj types.Object, c *gosec.Context) bool {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return refersTo(e.X, obj, c)
+	case *ast.Ident:
+		return obj != nil && c.Info.ObjectOf(e) == obj
+	case *ast.CallExpr:
+		if _, ok := gosec.MatchCallByPackage(e, c, "path/filepath", "Clean"); ok && len(e.Args) == 1 {
+			return refersTo(e.Args[0], obj, c)
+		}
+	}
+	return false
+}
+
+// constString returns the value of a constant string expression, e.g.
+// ".." or string(os.PathSeparator)
+func constString(expr ast.Expr, c *gosec.Context) (string, bool) {
+	if tv, ok := c.Info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value), true
+	}
+	return "", false
+}
+
+// isSeparator checks whether an expression is the path separator
+func isSeparator(expr ast.Expr, c *gosec.Context) bool {
+	value, ok := constString(expr, c)
+	return ok && (value == "/" || value == `\`)
+}
+
+// cleaned returns the argument of filepath.Clean, or the expression itself
+func cleaned(expr ast.Expr, c *gosec.Context) ast.Expr {
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.CallExpr:
+			if _, ok := gosec.MatchCallByPackage(e, c, "path/filepath", "Clean"); ok && len(e.Args) == 1 {
+				expr = e.Args[0]
+				continue
+			}
+		}
+		return expr
+	}
+}
+
+// bases returns the directories in which a path is joined: the first
+// argument of the join and, for a path joined in the directory of another
+// joined path, e.g. the target of a link, the directories of that path
+func bases(join *ast.CallExpr, body *ast.BlockStmt, c *gosec.Context) map[string]bool {
+	dirs := make(map[string]bool)
+	if len(join.Args) == 0 {
+		return dirs
+	}
+	base := cleaned(join.Args[0], c)
+	dirs[types.ExprString(base)] = true
+	if call, ok := gosec.MatchCallByPackage(base, c, "path/filepath", "Dir"); ok && len(call.Args) == 1 {
+		base = cleaned(call.Args[0], c)
+	}
+	ident, ok := base.(*ast.Ident)
+	if !ok {
+		return dirs
+	}
+	obj := c.Info.ObjectOf(ident)
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, lhs := range assign.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok && obj != nil && c.Info.ObjectOf(id) == obj {
+				if parent, ok := gosec.MatchCallByPackage(assign.Rhs[i], c, "path/filepath", "Join"); ok && parent != join {
+					for dir := range bases(parent, body, c) {
+						dirs[dir] = true
 					}
 				}
 			}
+		}
+		return true
+	})
+	return dirs
+}
+
+// isBasePrefix checks whether an expression is a cleaned base directory
+// followed by the path separator, e.g.
+// filepath.Clean(target)+string(os.PathSeparator)
+func isBasePrefix(expr ast.Expr, dirs map[string]bool, c *gosec.Context) bool {
+	binary, ok := expr.(*ast.BinaryExpr)
+	if !ok || binary.Op != token.ADD || !isSeparator(binary.Y, c) {
+		return false
+	}
+	call, ok := gosec.MatchCallByPackage(binary.X, c, "path/filepath", "Clean")
+	return ok && len(call.Args) == 1 && dirs[types.ExprString(cleaned(call.Args[0], c))]
+}
+
+// isParentPrefix checks whether an expression is "..", "../" or
+// ".."+string(os.PathSeparator)
+func isParentPrefix(expr ast.Expr, c *gosec.Context) bool {
+	value, ok := constString(expr, c)
+	return ok && (value == ".." || value == "../" || value == `..\`)
+}
+
+// relatives returns the variables holding the path relative to one of the
+// base directories, from rel, err := filepath.Rel(base, path)
+func relatives(path types.Object, dirs map[string]bool, body *ast.BlockStmt, c *gosec.Context) map[types.Object]*ast.AssignStmt {
+	rels := make(map[types.Object]*ast.AssignStmt)
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+			return true
+		}
+		call, ok := gosec.MatchCallByPackage(assign.Rhs[0], c, "path/filepath", "Rel")
+		if !ok || len(call.Args) != 2 || !dirs[types.ExprString(cleaned(call.Args[0], c))] || !refersTo(call.Args[1], path, c) {
+			return true
+		}
+		if rel, ok := assign.Lhs[0].(*ast.Ident); ok {
+			if obj := c.Info.ObjectOf(rel); obj != nil {
+				rels[obj] = assign
+			}
+		}
+		return true
+	})
+	return rels
+}
+
+// rejects checks whether a condition holds when the path leaves the base
+// directories: one of the operands of || is either
+// !strings.HasPrefix(path, filepath.Clean(base)+string(os.PathSeparator))
+// or strings.HasPrefix(rel, "..") for a path relative to a base
+func rejects(cond ast.Expr, path types.Object, dirs map[string]bool, rels map[types.Object]*ast.AssignStmt, c *gosec.Context) bool {
+	switch e := cond.(type) {
+	case *ast.ParenExpr:
+		return rejects(e.X, path, dirs, rels, c)
+	case *ast.BinaryExpr:
+		return e.Op == token.LOR && (rejects(e.X, path, dirs, rels, c) || rejects(e.Y, path, dirs, rels, c))
+	case *ast.UnaryExpr:
+		if e.Op != token.NOT {
+			return false
+		}
+		call, ok := gosec.MatchCallByPackage(cleaned(e.X, c), c, "strings", "HasPrefix")
+		return ok && len(call.Args) == 2 && refersTo(call.Args[0], path, c) && isBasePrefix(call.Args[1], dirs, c)
+	case *ast.CallExpr:
+		call, ok := gosec.MatchCallByPackage(e, c, "strings", "HasPrefix")
+		if !ok || len(call.Args) != 2 || !isParentPrefix(call.Args[1], c) {
+			return false
+		}
+		rel, ok := call.Args[0].(*ast.Ident)
+		if !ok {
+			return false
+		}
+		_, ok = rels[c.Info.ObjectOf(rel)]
+		return ok
+	}
+	return false
+}
+
+// terminates checks whether a block ends the function or the iteration
+func terminates(block *ast.BlockStmt) bool {
+	if len(block.List) == 0 {
+		return false
+	}
+	switch last := block.List[len(block.List)-1].(type) {
+	case *ast.ReturnStmt, *ast.BranchStmt:
+		return true
+	case *ast.ExprStmt:
+		if call, ok := last.X.(*ast.CallExpr); ok {
+			switch types.ExprString(call.Fun) {
+			case "panic", "os.Exit", "log.Fatal", "log.Fatalf", "log.Fatalln", "log.Panic", "log.Panicf", "log.Panicln":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// guards returns the if statements leaving the function, or the iteration,
+// when a path is not in its base directories
+func guards(path types.Object, dirs map[string]bool, body *ast.BlockStmt, c *gosec.Context) []*ast.IfStmt {
+	rels := relatives(path, dirs, body, c)
+	var found []*ast.IfStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		if stmt, ok := n.(*ast.IfStmt); ok && terminates(stmt.Body) && rejects(stmt.Cond, path, dirs, rels, c) {
+			found = append(found, stmt)
+		}
+		return true
+	})
+	return found
+}
+
+// dominates checks whether a statement is run before a node on every path
+// to the node: the node follows the statement in the same block
+func dominates(stmt ast.Stmt, n ast.Node, body *ast.BlockStmt) bool {
+	if n.Pos() < stmt.End() {
+		return false
+	}
+	found := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		var list []ast.Stmt
+		switch block := node.(type) {
+		case *ast.BlockStmt:
+			list = block.List
+		case *ast.CaseClause:
+			list = block.Body
+		case *ast.CommClause:
+			list = block.Body
+		default:
+			return !found
+		}
+		for _, s := range list {
+			if s == stmt {
+				found = node.Pos() <= n.Pos() && n.End() <= node.End()
+				return false
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// guarded checks whether a path joined in a base directory is validated
+// before it is used, with
+// strings.HasPrefix(filepath.Clean(path), filepath.Clean(base)+string(os.PathSeparator))
+// or with filepath.Rel(base, path) followed by a check for "..", leaving
+// the function when the path is outside of the base directory. When the
+// path is given to a call, e.g. os.Symlink, the call must be guarded,
+// otherwise all the uses of the path must be.
+func guarded(path types.Object, join *ast.CallExpr, call ast.Node, body *ast.BlockStmt, c *gosec.Context) bool {
+	dirs := bases(join, body, c)
+	ifs := guards(path, dirs, body, c)
+	if len(ifs) == 0 {
+		return false
+	}
+	isGuarded := func(n ast.Node) bool {
+		for _, stmt := range ifs {
+			if dominates(stmt, n, body) {
+				return true
+			}
+		}
+		return false
+	}
+	if call != join {
+		return isGuarded(call)
+	}
+	// the checks themselves use the path, e.g. in the error returned
+	checks := make([]ast.Node, 0, len(ifs))
+	for _, stmt := range ifs {
+		checks = append(checks, stmt)
+	}
+	for _, assign := range relatives(path, dirs, body, c) {
+		checks = append(checks, assign)
+	}
+	within := func(n ast.Node) bool {
+		for _, check := range checks {
+			if check.Pos() <= n.Pos() && n.End() <= check.End() {
+				return true
+			}
+		}
+		return false
+	}
+	safe := true
+	ast.Inspect(body, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok || c.Info.Uses[ident] != path || within(ident) {
+			return safe
+		}
+		safe = isGuarded(ident)
+		return safe
+	})
+	return safe
+}
+
+// sanitized checks whether a path joined from the archive entry field is
+// validated in the function before it is used, e.g. before a symbolic link
+// is created with the link name of a tar header
+func (a *archive) sanitized(n ast.Node, entry *ast.SelectorExpr, c *gosec.Context) bool {
+	body := enclosingFunc(n, c.Root)
+	if body == nil {
+		return false
+	}
+	name := types.ExprString(entry)
+	found := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		assign, ok := node.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return !found
+		}
+		for i, rhs := range assign.Rhs {
+			join := a.joins.ContainsCallExpr(rhs, c, false)
+			ident, ok := assign.Lhs[i].(*ast.Ident)
+			if join == nil || !ok {
+				continue
+			}
+			for _, arg := range join.Args {
+				if e := a.entryName(arg, c); e != nil && types.ExprString(e) == name {
+					found = found || guarded(c.Info.ObjectOf(ident), join, n, body, c)
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
 
-			if argType != nil && argType.String() == a.argType {
+// Match inspects AST nodes to determine if a path joined, or a link
+// created, uses any argument derived from the name of an archive entry
+// without checking that it stays in the extraction directory
+func (a *archive) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	if node := a.calls.ContainsCallExpr(n, c, false); node != nil {
+		for _, arg := range node.Args {
+			if entry := a.entryName(arg, c); entry != nil && !a.sanitized(n, entry, c) {
 				return gosec.NewIssue(c, n, a.ID(), a.What, a.Severity, a.Confidence), nil
 			}
 		}
@@ -43,18 +386,34 @@ func (a *archive) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
 	return nil, nil
 }
 
-// NewArchive creates a new rule which detects the file traversal when extracting zip archives
+// NewArchive creates a new rule which detects the file traversal when extracting zip or tar archives
 func NewArchive(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
+	joins := gosec.NewCallList()
+	joins.Add("path/filepath", "Join")
+
 	calls := gosec.NewCallList()
 	calls.Add("path/filepath", "Join")
+	calls.AddAll("os", "Symlink", "Link")
+
+	cleans := gosec.NewCallList()
+	cleans.AddAll("path/filepath", "Clean", "FromSlash", "ToSlash")
+
 	return &archive{
-		calls:   calls,
-		argType: "*archive/zip.File",
+		calls:  calls,
+		joins:  joins,
+		cleans: cleans,
+		argTypes: map[string]bool{
+			"*archive/zip.File":       true,
+			"archive/zip.FileHeader":  true,
+			"*archive/zip.FileHeader": true,
+			"*archive/tar.Header":     true,
+			"archive/tar.Header":      true,
+		},
 		MetaData: gosec.MetaData{
 			ID:         id,
 			Severity:   gosec.Medium,
 			Confidence: gosec.High,
-			What:       "File traversal when extracting zip archive",
+			What:       "File traversal when extracting archive",
 		},
 	}, []ast.Node{(*ast.CallExpr)(nil)}
 }
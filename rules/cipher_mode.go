@@ -0,0 +1,268 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/securego/gosec"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+type weakCipherMode struct {
+	gosec.MetaData
+	ivs     map[string]map[string]int // calls taking an IV or a nonce, with its position
+	ciphers gosec.CallList            // calls creating a block cipher from a key
+	blocks  map[string]bool           // block cipher types
+	single  []string                  // block cipher methods working on a single block
+}
+
+// ID returns the identifier for this rule
+func (w *weakCipherMode) ID() string {
+	return w.MetaData.ID
+}
+
+// methodCall returns the type of the value a method is called on
+func methodCall(call *ast.CallExpr, c *gosec.Context) (string, string, bool) {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", false
+	}
+	if ident, ok := selector.X.(*ast.Ident); ok {
+		if _, isPkg := c.Info.ObjectOf(ident).(*types.PkgName); isPkg {
+			return "", "", false
+		}
+	}
+	recv := c.Info.TypeOf(selector.X)
+	if recv == nil {
+		return "", "", false
+	}
+	return recv.String(), selector.Sel.Name, true
+}
+
+// hardcoded checks whether a byte slice is a constant, either built from a
+// literal, or allocated and never filled, e.g. with zero bytes
+func (w *weakCipherMode) hardcoded(expr ast.Expr, c *gosec.Context) bool {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return w.hardcoded(e.X, c)
+	case *ast.SliceExpr:
+		return w.hardcoded(e.X, c)
+	case *ast.CompositeLit:
+		return gosec.TryResolve(e, c)
+	case *ast.CallExpr:
+		if tv, ok := c.Info.Types[e.Fun]; ok && tv.IsType() && len(e.Args) == 1 {
+			// a conversion, e.g. []byte("secret")
+			return w.hardcoded(e.Args[0], c)
+		}
+		if ident, ok := e.Fun.(*ast.Ident); ok && ident.Name == "make" {
+			return true
+		}
+		return false
+	case *ast.BasicLit:
+		return true
+	case *ast.Ident:
+		if tv, ok := c.Info.Types[e]; ok && tv.Value != nil {
+			return true
+		}
+		obj, ok := c.Info.ObjectOf(e).(*types.Var)
+		if !ok {
+			return false
+		}
+		ident, file, value := declaration(obj, c)
+		if value == nil {
+			return false
+		}
+		return w.hardcoded(value, c) && !w.filled(obj, ident, file, c)
+	}
+	return false
+}
+
+// declaration finds the identifier declaring a variable in the files of the
+// package, and the value it is initialized with, if any
+func declaration(obj *types.Var, c *gosec.Context) (*ast.Ident, *ast.File, ast.Expr) {
+	for _, file := range c.PkgFiles {
+		if obj.Pos() < file.Pos() || obj.Pos() > file.End() {
+			continue
+		}
+		path, _ := astutil.PathEnclosingInterval(file, obj.Pos(), obj.Pos())
+		for _, node := range path {
+			switch decl := node.(type) {
+			case *ast.AssignStmt:
+				for i, lhs := range decl.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok && c.Info.Defs[ident] == obj {
+						if len(decl.Lhs) != len(decl.Rhs) {
+							return ident, file, nil
+						}
+						return ident, file, decl.Rhs[i]
+					}
+				}
+				return nil, file, nil
+			case *ast.ValueSpec:
+				for i, name := range decl.Names {
+					if c.Info.Defs[name] == obj {
+						if i >= len(decl.Values) {
+							return name, file, nil
+						}
+						return name, file, decl.Values[i]
+					}
+				}
+				return nil, file, nil
+			}
+		}
+		return nil, file, nil
+	}
+	return nil, nil, nil
+}
+
+// filled checks whether a byte slice is written or reassigned after its
+// declaration, e.g. with io.ReadFull(rand.Reader, iv), copy or an index
+// assignment, in its function or, for a package variable, in the package
+func (w *weakCipherMode) filled(obj *types.Var, ident *ast.Ident, file *ast.File, c *gosec.Context) bool {
+	var scope []ast.Node
+	if obj.Parent() == obj.Pkg().Scope() {
+		for _, f := range c.PkgFiles {
+			scope = append(scope, f)
+		}
+	} else if body := enclosingFunc(ident, file); body != nil {
+		scope = append(scope, body)
+	}
+	refers := func(expr ast.Expr) bool {
+		for {
+			switch e := expr.(type) {
+			case *ast.SliceExpr:
+				expr = e.X
+			case *ast.IndexExpr:
+				expr = e.X
+			case *ast.ParenExpr:
+				expr = e.X
+			case *ast.Ident:
+				return c.Info.Uses[e] == obj
+			default:
+				return false
+			}
+		}
+	}
+	found := false
+	for _, node := range scope {
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CallExpr:
+				if w.usesIV(node, c) >= 0 || w.ciphers.ContainsCallExpr(node, c, false) != nil {
+					return true
+				}
+				for _, arg := range node.Args {
+					found = found || refers(arg)
+				}
+			case *ast.AssignStmt:
+				for _, lhs := range node.Lhs {
+					found = found || refers(lhs)
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// usesIV returns the position of the IV or nonce argument of a call, or -1
+func (w *weakCipherMode) usesIV(call *ast.CallExpr, c *gosec.Context) int {
+	if selector, name, ok := calleeOf(call, c); ok {
+		if pos, ok := w.ivs[selector][name]; ok {
+			return pos
+		}
+	}
+	if recv, name, ok := methodCall(call, c); ok {
+		if pos, ok := w.ivs[recv][name]; ok {
+			return pos
+		}
+	}
+	return -1
+}
+
+// inLoop checks whether a node is executed in a loop of its function
+func inLoop(n ast.Node, c *gosec.Context) bool {
+	path, _ := astutil.PathEnclosingInterval(c.Root, n.Pos(), n.End())
+	for _, node := range path {
+		switch node.(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			return true
+		case *ast.FuncDecl, *ast.FuncLit:
+			return false
+		}
+	}
+	return false
+}
+
+// Match inspects the calls using a block cipher: the IVs and nonces given
+// to the block modes, the keys given to the ciphers, and the blocks
+// encrypted one by one in a loop, which is the ECB mode
+func (w *weakCipherMode) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		return nil, nil
+	}
+	if pos := w.usesIV(call, c); pos >= 0 && pos < len(call.Args) {
+		if w.hardcoded(call.Args[pos], c) {
+			return gosec.NewIssue(c, n, w.ID(), "Use of a hardcoded IV or nonce with a block cipher", w.Severity, w.Confidence), nil
+		}
+		return nil, nil
+	}
+	if w.ciphers.ContainsCallExpr(n, c, false) != nil && len(call.Args) > 0 {
+		if w.hardcoded(call.Args[0], c) {
+			return gosec.NewIssue(c, n, w.ID(), "Use of a hardcoded key with a block cipher", w.Severity, w.Confidence), nil
+		}
+		return nil, nil
+	}
+	if recv, name, ok := methodCall(call, c); ok && w.blocks[recv] {
+		for _, method := range w.single {
+			if name == method && inLoop(n, c) {
+				return gosec.NewIssue(c, n, w.ID(), "Use of a block cipher in ECB mode", w.Severity, gosec.Medium), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// NewWeakCipherMode detects the misuses of block ciphers: hardcoded IVs,
+// nonces or keys, and the ECB mode
+func NewWeakCipherMode(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
+	ciphers := gosec.NewCallList()
+	ciphers.Add("crypto/aes", "NewCipher")
+
+	return &weakCipherMode{
+		ivs: map[string]map[string]int{
+			"crypto/cipher": {
+				"NewCBCEncrypter": 1,
+				"NewCTR":          1,
+				"NewOFB":          1,
+				"NewCFBEncrypter": 1,
+			},
+			"crypto/cipher.AEAD": {
+				"Seal": 1,
+			},
+		},
+		ciphers: ciphers,
+		blocks: map[string]bool{
+			"crypto/cipher.Block": true,
+		},
+		single: []string{"Encrypt", "Decrypt"},
+		MetaData: gosec.MetaData{
+			ID:         id,
+			Severity:   gosec.High,
+			Confidence: gosec.High,
+			What:       "Weak use of a block cipher mode",
+		},
+	}, []ast.Node{(*ast.CallExpr)(nil)}
+}
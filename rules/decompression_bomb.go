@@ -0,0 +1,212 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/securego/gosec"
+)
+
+type decompressionBomb struct {
+	gosec.MetaData
+	reads         gosec.CallList // calls reading a whole reader
+	readers       gosec.CallList // calls creating a decompressing reader
+	wrappers      gosec.CallList // calls wrapping a reader
+	limits        gosec.CallList // calls bounding a reader
+	readerTypes   map[string]bool
+	limitedReader string
+}
+
+// ID returns the identifier for this rule
+func (d *decompressionBomb) ID() string {
+	return d.MetaData.ID
+}
+
+// calleeOf returns the package path, or the receiver type of a method, and
+// the name of the function called
+func calleeOf(call *ast.CallExpr, c *gosec.Context) (string, string, bool) {
+	_, obj := gosec.GetCallObject(call, c)
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return "", "", false
+	}
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		return recv.Type().String(), fn.Name(), true
+	}
+	return fn.Pkg().Path(), fn.Name(), true
+}
+
+func (d *decompressionBomb) isCall(calls gosec.CallList, call *ast.CallExpr, c *gosec.Context) bool {
+	selector, name, ok := calleeOf(call, c)
+	return ok && calls.Contains(selector, name)
+}
+
+// unbounded checks whether an expression is a decompressing reader which
+// is not bounded, following the local variables it is assigned from
+func (d *decompressionBomb) unbounded(expr ast.Expr, c *gosec.Context, seen map[types.Object]bool) bool {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return d.unbounded(e.X, c, seen)
+	case *ast.CallExpr:
+		switch {
+		case d.isCall(d.limits, e, c):
+			return false
+		case d.isCall(d.readers, e, c):
+			return true
+		case d.isCall(d.wrappers, e, c):
+			for _, arg := range e.Args {
+				if d.unbounded(arg, c, seen) {
+					return true
+				}
+			}
+			return false
+		}
+	case *ast.Ident:
+		obj, ok := c.Info.ObjectOf(e).(*types.Var)
+		if !ok || seen[obj] {
+			return false
+		}
+		seen[obj] = true
+		values := assignedValues(obj, c.Root, c)
+		for _, value := range values {
+			if d.limited(value, c) {
+				return false
+			}
+		}
+		if d.readerTypes[obj.Type().String()] {
+			return true
+		}
+		for _, value := range values {
+			if d.unbounded(value, c, seen) {
+				return true
+			}
+		}
+		return false
+	}
+	if t := c.Info.TypeOf(expr); t != nil {
+		return d.readerTypes[t.String()]
+	}
+	return false
+}
+
+// limited checks whether a value assigned to a reader bounds it
+func (d *decompressionBomb) limited(value ast.Expr, c *gosec.Context) bool {
+	switch v := value.(type) {
+	case *ast.CallExpr:
+		return d.isCall(d.limits, v, c)
+	case *ast.UnaryExpr:
+		return d.limited(v.X, c)
+	case *ast.CompositeLit:
+		t := c.Info.TypeOf(v)
+		return t != nil && t.String() == d.limitedReader
+	}
+	return false
+}
+
+// assignedValues returns the expressions assigned to a variable in a file
+func assignedValues(obj types.Object, file *ast.File, c *gosec.Context) []ast.Expr {
+	var values []ast.Expr
+	add := func(lhs []ast.Expr, rhs []ast.Expr) {
+		for i, target := range lhs {
+			ident, ok := target.(*ast.Ident)
+			if !ok || c.Info.ObjectOf(ident) != obj {
+				continue
+			}
+			if len(lhs) == len(rhs) {
+				values = append(values, rhs[i])
+			} else if len(rhs) == 1 && i == 0 {
+				// the first result of a call, e.g. r, err := gzip.NewReader(f)
+				values = append(values, rhs[0])
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			add(node.Lhs, node.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(node.Names))
+			for i, name := range node.Names {
+				lhs[i] = name
+			}
+			add(lhs, node.Values)
+		}
+		return true
+	})
+	return values
+}
+
+// Match inspects the calls reading a whole reader, such as io.Copy or
+// ioutil.ReadAll, and reports them when the reader decompresses data
+// without a bound
+func (d *decompressionBomb) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok || !d.isCall(d.reads, call, c) || len(call.Args) == 0 {
+		return nil, nil
+	}
+	// io.Copy and io.CopyBuffer read their second argument
+	src := call.Args[0]
+	if len(call.Args) > 1 {
+		src = call.Args[1]
+	}
+	if d.unbounded(src, c, make(map[types.Object]bool)) {
+		return gosec.NewIssue(c, n, d.ID(), d.What, d.Severity, d.Confidence), nil
+	}
+	return nil, nil
+}
+
+// NewDecompressionBomb creates a rule which detects the archives and the
+// compressed streams read without a bound on the decompressed size
+func NewDecompressionBomb(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
+	reads := gosec.NewCallList()
+	reads.AddAll("io", "Copy", "CopyBuffer", "ReadAll")
+	reads.Add("io/ioutil", "ReadAll")
+
+	readers := gosec.NewCallList()
+	readers.Add("compress/gzip", "NewReader")
+	readers.Add("compress/zlib", "NewReader")
+	readers.Add("compress/zlib", "NewReaderDict")
+	readers.Add("compress/flate", "NewReader")
+	readers.Add("compress/flate", "NewReaderDict")
+	readers.Add("compress/bzip2", "NewReader")
+	readers.Add("compress/lzw", "NewReader")
+	readers.Add("archive/tar", "NewReader")
+	readers.Add("*archive/zip.File", "Open")
+
+	wrappers := gosec.NewCallList()
+	wrappers.AddAll("bufio", "NewReader", "NewReaderSize")
+	wrappers.AddAll("io", "TeeReader", "MultiReader")
+
+	limits := gosec.NewCallList()
+	limits.Add("io", "LimitReader")
+
+	return &decompressionBomb{
+		reads:    reads,
+		readers:  readers,
+		wrappers: wrappers,
+		limits:   limits,
+		readerTypes: map[string]bool{
+			"*compress/gzip.Reader": true,
+			"*archive/tar.Reader":   true,
+		},
+		limitedReader: "io.LimitedReader",
+		MetaData: gosec.MetaData{
+			ID:         id,
+			Severity:   gosec.Medium,
+			Confidence: gosec.Medium,
+			What:       "Potential DoS vulnerability via decompression bomb",
+		},
+	}, []ast.Node{(*ast.CallExpr)(nil)}
+}
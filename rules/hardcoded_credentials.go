@@ -23,6 +23,10 @@ import (
 	"github.com/securego/gosec"
 )
 
+// credentialPattern matches the names of the variables and functions
+// related to credentials
+const credentialPattern = `(?i)passwd|pass|password|pwd|secret|token`
+
 type credentials struct {
 	gosec.MetaData
 	pattern          *regexp.Regexp
@@ -99,7 +103,7 @@ func (r *credentials) matchValueSpec(valueSpec *ast.ValueSpec, ctx *gosec.Contex
 // NewHardcodedCredentials attempts to find high entropy string constants being
 // assigned to variables that appear to be related to credentials.
 func NewHardcodedCredentials(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
-	pattern := `(?i)passwd|pass|password|pwd|secret|token`
+	pattern := credentialPattern
 	entropyThreshold := 80.0
 	perCharThreshold := 3.0
 	ignoreEntropy := false
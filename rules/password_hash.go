@@ -0,0 +1,226 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"regexp"
+	"strconv"
+
+	"github.com/securego/gosec"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// kdfParam is a parameter of a key derivation function with its minimum
+type kdfParam struct {
+	arg  int
+	name string
+	min  int64
+}
+
+type weakPasswordHash struct {
+	gosec.MetaData
+	pattern *regexp.Regexp
+	hashes  gosec.CallList
+	kdfs    map[string]map[string][]kdfParam
+}
+
+// ID returns the identifier for this rule
+func (w *weakPasswordHash) ID() string {
+	return w.MetaData.ID
+}
+
+// intValue resolves the constant integer value of an expression
+func intValue(expr ast.Expr, c *gosec.Context) (int64, bool) {
+	if tv, ok := c.Info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.Int {
+		return constant.Int64Val(tv.Value)
+	}
+	if value, err := gosec.GetInt(expr); err == nil {
+		return value, true
+	}
+	return 0, false
+}
+
+// namedArgs checks whether an argument of a call uses a variable named
+// like a credential
+func (w *weakPasswordHash) namedArgs(call *ast.CallExpr, c *gosec.Context) bool {
+	found := false
+	for _, arg := range call.Args {
+		ast.Inspect(arg, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok && c.Info.Uses[ident] != nil {
+				found = found || w.pattern.MatchString(ident.Name)
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// written checks whether a credential is written to a hash, e.g. with
+// h.Write(password), in the function the hash is created in
+func (w *weakPasswordHash) written(hash *ast.Ident, c *gosec.Context) bool {
+	obj := c.Info.ObjectOf(hash)
+	body := enclosingFunc(hash, c.Root)
+	if obj == nil || body == nil {
+		return false
+	}
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if selector, ok := call.Fun.(*ast.SelectorExpr); ok && (selector.Sel.Name == "Write" || selector.Sel.Name == "WriteString") {
+				if ident, ok := selector.X.(*ast.Ident); ok && c.Info.ObjectOf(ident) == obj {
+					found = found || w.namedArgs(call, c)
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// credential checks whether a call hashes a credential: one of its
+// arguments, the data written to the hash, the variable it is assigned to
+// or the function it is called in is named like a credential
+func (w *weakPasswordHash) credential(call *ast.CallExpr, c *gosec.Context) bool {
+	if w.namedArgs(call, c) {
+		return true
+	}
+	path, _ := astutil.PathEnclosingInterval(c.Root, call.Pos(), call.End())
+	for _, node := range path {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && (w.pattern.MatchString(ident.Name) || w.written(ident, c)) {
+					return true
+				}
+			}
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				if w.pattern.MatchString(name.Name) {
+					return true
+				}
+			}
+		case *ast.FuncDecl:
+			return w.pattern.MatchString(n.Name.Name)
+		}
+	}
+	return false
+}
+
+// Match inspects the hashes of the credentials, and the parameters given to
+// the key derivation functions
+func (w *weakPasswordHash) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		return nil, nil
+	}
+	if pkg, name, ok := calleeOf(call, c); ok {
+		for _, param := range w.kdfs[pkg][name] {
+			if param.arg >= len(call.Args) {
+				continue
+			}
+			if value, ok := intValue(call.Args[param.arg], c); ok && value < param.min {
+				what := fmt.Sprintf("Weak %s of %d for %s, it should be at least %d", param.name, value, name, param.min)
+				return gosec.NewIssue(c, n, w.ID(), what, w.Severity, gosec.High), nil
+			}
+		}
+	}
+	if w.hashes.ContainsCallExpr(n, c, false) != nil && w.credential(call, c) {
+		return gosec.NewIssue(c, n, w.ID(), w.What, w.Severity, w.Confidence), nil
+	}
+	return nil, nil
+}
+
+// ruleOptions returns the options of a rule section of the configuration,
+// either set in code or decoded from a JSON file, and nil for any other value
+func ruleOptions(section interface{}) map[string]string {
+	switch values := section.(type) {
+	case map[string]string:
+		return values
+	case map[string]interface{}:
+		options := make(map[string]string, len(values))
+		for name, value := range values {
+			switch v := value.(type) {
+			case string:
+				options[name] = v
+			case float64:
+				options[name] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		return options
+	}
+	return nil
+}
+
+// NewWeakPasswordHash detects the passwords hashed with a fast hash function,
+// and the weak parameters of the key derivation functions
+func NewWeakPasswordHash(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
+	pattern := credentialPattern
+	thresholds := map[string]int64{
+		"bcrypt_cost":       10,
+		"scrypt_n":          1 << 15,
+		"argon2_time":       2,
+		"argon2_memory":     19 * 1024,
+		"pbkdf2_iterations": 10000,
+	}
+	if configPattern, ok := ruleOptions(conf["hardcreds"])["pattern"]; ok {
+		pattern = configPattern
+	}
+	options := ruleOptions(conf["weak-password-hash"])
+	for name := range thresholds {
+		if configThreshold, ok := options[name]; ok {
+			if parsedInt, err := strconv.ParseInt(configThreshold, 10, 64); err == nil {
+				thresholds[name] = parsedInt
+			}
+		}
+	}
+
+	hashes := gosec.NewCallList()
+	hashes.AddAll("crypto/md5", "New", "Sum")
+	hashes.AddAll("crypto/sha1", "New", "Sum")
+	hashes.AddAll("crypto/sha256", "New", "New224", "Sum256", "Sum224")
+	hashes.AddAll("crypto/sha512", "New", "New384", "New512_224", "New512_256", "Sum512", "Sum384", "Sum512_224", "Sum512_256")
+	hashes.Add("crypto/hmac", "New")
+
+	argon2 := []kdfParam{
+		{2, "time", thresholds["argon2_time"]},
+		{3, "memory", thresholds["argon2_memory"]},
+	}
+	return &weakPasswordHash{
+		pattern: regexp.MustCompile(pattern),
+		hashes:  hashes,
+		kdfs: map[string]map[string][]kdfParam{
+			"golang.org/x/crypto/bcrypt": {
+				"GenerateFromPassword": {{1, "cost", thresholds["bcrypt_cost"]}},
+			},
+			"golang.org/x/crypto/scrypt": {
+				"Key": {{2, "N", thresholds["scrypt_n"]}},
+			},
+			"golang.org/x/crypto/argon2": {
+				"Key":   argon2,
+				"IDKey": argon2,
+			},
+			"golang.org/x/crypto/pbkdf2": {
+				"Key": {{2, "iterations", thresholds["pbkdf2_iterations"]}},
+			},
+		},
+		MetaData: gosec.MetaData{
+			ID:         id,
+			Severity:   gosec.Medium,
+			Confidence: gosec.Low,
+			What:       "Password hashed with a fast hash function instead of a key derivation function",
+		},
+	}, []ast.Node{(*ast.CallExpr)(nil)}
+}
@@ -56,29 +56,37 @@ func (r *readfile) isJoinFunc(n ast.Node, c *gosec.Context) bool {
 	return false
 }
 
-// Match inspects AST nodes to determine if the match the methods `os.Open` or `ioutil.ReadFile`
+// Match inspects AST nodes to determine if the match the methods `os.Open` or `ioutil.ReadFile`.
+// The path is reported with a high confidence when the taint analysis proves that it holds user input.
 func (r *readfile) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
 	if node := r.ContainsCallExpr(n, c, false); node != nil {
+		for _, arg := range node.Args {
+			if trace, ok := c.Tainted[arg]; ok {
+				issue := gosec.NewIssue(c, n, r.ID(), "File inclusion via user input", r.Severity, r.Confidence)
+				issue.Trace = trace
+				return issue, nil
+			}
+		}
 		for _, arg := range node.Args {
 			// handles path joining functions in Arg
 			// eg. os.Open(filepath.Join("/tmp/", file))
 			if callExpr, ok := arg.(*ast.CallExpr); ok {
 				if r.isJoinFunc(callExpr, c) {
-					return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, r.Confidence), nil
+					return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, gosec.Low), nil
 				}
 			}
 			// handles binary string concatenation eg. ioutil.Readfile("/tmp/" + file + "/blob")
 			if binExp, ok := arg.(*ast.BinaryExpr); ok {
 				// resolve all found identities from the BinaryExpr
 				if _, ok := gosec.FindVarIdentities(binExp, c); ok {
-					return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, r.Confidence), nil
+					return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, gosec.Low), nil
 				}
 			}
 
 			if ident, ok := arg.(*ast.Ident); ok {
 				obj := c.Info.ObjectOf(ident)
 				if _, ok := obj.(*types.Var); ok && !gosec.TryResolve(ident, c) {
-					return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, r.Confidence), nil
+					return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, gosec.Low), nil
 				}
 			}
 		}
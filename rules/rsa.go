@@ -17,8 +17,11 @@ package rules
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
+	"go/types"
 
 	"github.com/securego/gosec"
+	"golang.org/x/tools/go/ssa"
 )
 
 type weakKeyStrength struct {
@@ -32,6 +35,11 @@ func (w *weakKeyStrength) ID() string {
 }
 
 func (w *weakKeyStrength) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	// the key size is resolved by MatchSSA when the SSA form of the
+	// package is available
+	if c.SSA != nil {
+		return nil, nil
+	}
 	if callExpr := w.calls.ContainsCallExpr(n, c, false); callExpr != nil {
 		if bits, err := gosec.GetInt(callExpr.Args[1]); err == nil && bits < (int64)(w.bits) {
 			return gosec.NewIssue(c, n, w.ID(), w.What, w.Severity, w.Confidence), nil
@@ -40,6 +48,37 @@ func (w *weakKeyStrength) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, err
 	return nil, nil
 }
 
+// Instructions returns the SSA instructions checked by the rule
+func (w *weakKeyStrength) Instructions() []ssa.Instruction {
+	return []ssa.Instruction{(*ssa.Call)(nil)}
+}
+
+// MatchSSA checks the key size passed to the key generation, resolving the
+// values held in variables, struct fields and package variables
+func (w *weakKeyStrength) MatchSSA(instr ssa.Instruction, c *gosec.Context) (*gosec.Issue, error) {
+	call := instr.(*ssa.Call)
+	callee := call.Call.StaticCallee()
+	if callee == nil || len(call.Call.Args) < 2 {
+		return nil, nil
+	}
+	fn, ok := callee.Object().(*types.Func)
+	if !ok || fn.Pkg() == nil || !w.calls.Contains(fn.Pkg().Path(), fn.Name()) {
+		return nil, nil
+	}
+	value := gosec.ConstValue(call.Call.Args[1])
+	if value == nil || value.Kind() != constant.Int {
+		return nil, nil
+	}
+	if bits, ok := constant.Int64Val(value); ok && bits < int64(w.bits) {
+		for _, n := range gosec.GetNodePath(call.Pos(), c) {
+			if _, ok := n.(*ast.CallExpr); ok {
+				return gosec.NewIssue(c, n, w.ID(), w.What, w.Severity, w.Confidence), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
 // NewWeakKeyStrength builds a rule that detects RSA keys < 2048 bits
 func NewWeakKeyStrength(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
 	calls := gosec.NewCallList()
@@ -36,6 +36,15 @@ func (rl RuleList) Builders() map[string]gosec.RuleBuilder {
 	return builders
 }
 
+// IDs returns the rule ID's of a given rule list
+func (rl RuleList) IDs() []string {
+	ids := make([]string, 0, len(rl))
+	for id := range rl {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 // RuleFilter can be used to include or exclude a rule depending on the return
 // value of the function
 type RuleFilter func(string) bool
@@ -66,6 +75,7 @@ func Generate(filters ...RuleFilter) RuleList {
 		{"math-audit", "Audit the use of big.Exp function", NewUsingBigExp},
 		{"insecure-ssh-key", "Audit the use of ssh.InsecureIgnoreHostKey function", NewSSHHostKey},
 		{"taint-http", "Url provided to HTTP request as taint input", NewSSRFCheck},
+		{"slowloris", "HTTP server without read timeouts, exposed to Slowloris attacks", NewSlowloris},
 
 		// injection
 		{"sql-format-string", "SQL query construction using format string", NewSQLStrFormat},
@@ -78,12 +88,15 @@ func Generate(filters ...RuleFilter) RuleList {
 		{"poor-chmod", "Poor file permissions used when creation file or using chmod", NewFilePerms},
 		{"predict-path", "Creating tempfile using a predictable path", NewBadTempFile},
 		{"taint-file-path", "File path provided as taint input", NewReadFile},
-		{"file-traverse", "File path traversal when extracting zip archive", NewArchive},
+		{"file-traverse", "File path traversal when extracting zip or tar archives", NewArchive},
+		{"decompression-bomb", "Decompression bomb when reading archives or compressed data without a limit", NewDecompressionBomb},
 
 		// crypto
 		{"insecure-lib", "Detect the usage of DES, RC4, MD5 or SHA1", NewUsesWeakCryptography},
+		{"weak-cipher-mode", "Detect hardcoded IVs, nonces or keys and the ECB mode with block ciphers", NewWeakCipherMode},
 		{"bad-tls", "Look for bad TLS connection settings", NewIntermediateTLSCheck},
 		{"min-key-rsa", "Ensure minimum RSA key length of 2048 bits", NewWeakKeyStrength},
+		{"weak-password-hash", "Passwords hashed with a fast hash function or weak key derivation parameters", NewWeakPasswordHash},
 		{"insecure-rand", "Insecure random number source (rand)", NewWeakRandCheck},
 
 		// blacklist
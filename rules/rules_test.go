@@ -3,6 +3,8 @@ package rules_test
 import (
 	"fmt"
 	"log"
+	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -30,24 +32,38 @@ var _ = Describe("gosec rules", func() {
 	BeforeEach(func() {
 		logger, _ = testutils.NewLogger()
 		config = gosec.NewConfig()
-		analyzer = gosec.NewAnalyzer(config, logger)
+		analyzer = gosec.NewAnalyzer(config, logger, false)
 		runner = func(rule string, samples []testutils.CodeSample, options ...option) {
 			for _, o := range options {
 				config.SetGlobal(o.name, o.value)
 			}
 			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, rule)).Builders())
+			// the samples are written in their own packages and checked
+			// together, so that the packages they import are loaded once
+			paths := make([]string, len(samples))
 			for n, sample := range samples {
-				analyzer.Reset()
 				pkg := testutils.NewTestPackage()
 				defer pkg.Close()
 				for i, code := range sample.Code {
 					pkg.AddFile(fmt.Sprintf("sample_%d_%d.go", n, i), code)
 				}
-				err := pkg.Build()
-				Expect(err).ShouldNot(HaveOccurred())
-				err = analyzer.Process(buildTags, pkg.Path)
+				err := pkg.Write()
 				Expect(err).ShouldNot(HaveOccurred())
-				issues, _, _ := analyzer.Report()
+				paths[n] = pkg.Path
+			}
+			err := analyzer.Process(buildTags, paths...)
+			Expect(err).ShouldNot(HaveOccurred())
+			reported, _, errors, _ := analyzer.Report()
+			Expect(errors).Should(BeEmpty())
+			for n, sample := range samples {
+				// count only the issues of the rule under test, not the flows
+				// the taint analysis reports on the same samples
+				var issues []*gosec.Issue
+				for _, issue := range reported {
+					if issue.RuleID == rule && filepath.Dir(issue.File) == paths[n] {
+						issues = append(issues, issue)
+					}
+				}
 				if len(issues) != sample.Errors {
 					fmt.Println(sample.Code)
 				}
@@ -58,112 +74,204 @@ var _ = Describe("gosec rules", func() {
 
 	Context("report correct errors for all samples", func() {
 		It("should detect hardcoded credentials", func() {
-			runner("hardcreds", testutils.SampleCodehardcreds)
+			runner("hardcreds", testutils.SampleCodeHardcreds)
 		})
 
 		It("should detect binding to all network interfaces", func() {
-			runner("bind-interfaces", testutils.SampleCodebind-interfaces)
+			runner("bind-interfaces", testutils.SampleCodeBindInterfaces)
 		})
 
 		It("should use of unsafe block", func() {
-			runner("unsafe-block", testutils.SampleCodeunsafe-block)
+			runner("unsafe-block", testutils.SampleCodeUnsafeBlock)
 		})
 
 		It("should detect errors not being checked", func() {
-			runner("error-check", testutils.SampleCodeerror-check)
+			runner("error-check", testutils.SampleCodeErrorCheck)
 		})
 
 		It("should detect errors not being checked in audit mode", func() {
-			runner("error-check", testutils.SampleCodeerror-checkAudit, option{name: gosec.Audit, value: "enabled"})
+			runner("error-check", testutils.SampleCodeErrorCheckAudit, option{name: gosec.Audit, value: "enabled"})
 		})
 
 		It("should detect of big.Exp function", func() {
-			runner("math-audit", testutils.SampleCodemath-audit)
+			runner("math-audit", testutils.SampleCodeMathAudit)
 		})
 
 		It("should detect of ssh.InsecureIgnoreHostKey function", func() {
-			runner("insecure-ssh-key", testutils.SampleCodeinsecure-ssh-key)
+			runner("insecure-ssh-key", testutils.SampleCodeInsecureSshKey)
 		})
 
 		It("should detect ssrf via http requests with variable url", func() {
-			runner("taint-http", testutils.SampleCodetaint-http)
+			runner("taint-http", testutils.SampleCodeTaintHttp)
+		})
+
+		It("should detect HTTP servers without read timeouts", func() {
+			runner("slowloris", testutils.SampleCodeSlowloris)
 		})
 
 		It("should detect sql injection via format strings", func() {
-			runner("sql-format-string", testutils.SampleCodesql-format-string)
+			runner("sql-format-string", testutils.SampleCodeSqlFormatString)
 		})
 
 		It("should detect sql injection via string concatenation", func() {
-			runner("sql-string-concat", testutils.SampleCodesql-string-concat)
+			runner("sql-string-concat", testutils.SampleCodeSqlStringConcat)
 		})
 
 		It("should detect unescaped html in templates", func() {
-			runner("unescaped-html-data", testutils.SampleCodeunescaped-html-data)
+			runner("unescaped-html-data", testutils.SampleCodeUnescapedHtmlData)
 		})
 
 		It("should detect command execution", func() {
-			runner("cmd-exec", testutils.SampleCodecmd-exec)
+			runner("cmd-exec", testutils.SampleCodeCmdExec)
 		})
 
 		It("should detect poor file permissions on mkdir", func() {
-			runner("dir-perm", testutils.SampleCodedir-perm)
+			runner("dir-perm", testutils.SampleCodeDirPerm)
 		})
 
 		It("should detect poor permissions when creating or chmod a file", func() {
-			runner("poor-chmod", testutils.SampleCodepoor-chmod)
+			runner("poor-chmod", testutils.SampleCodePoorChmod)
 		})
 
 		It("should detect insecure temp file creation", func() {
-			runner("predict-path", testutils.SampleCodepredict-path)
+			runner("predict-path", testutils.SampleCodePredictPath)
 		})
 
 		It("should detect file path provided as taint input", func() {
-			runner("taint-file-path", testutils.SampleCodetaint-file-path)
+			runner("taint-file-path", testutils.SampleCodeTaintFilePath)
+		})
+
+		It("should detect file path traversal when extracting zip or tar archives", func() {
+			runner("file-traverse", testutils.SampleCodeFileTraverse)
 		})
 
-		It("should detect file path traversal when extracting zip archive", func() {
-			runner("file-traverse", testutils.SampleCodefile-traverse)
+		It("should detect decompression bombs", func() {
+			runner("decompression-bomb", testutils.SampleCodeDecompressionBomb)
 		})
 
 		It("should detect weak crypto algorithms", func() {
-			runner("insecure-lib", testutils.SampleCodeinsecure-lib)
+			runner("insecure-lib", testutils.SampleCodeInsecureLib)
 		})
 
 		It("should detect weak crypto algorithms", func() {
-			runner("insecure-lib", testutils.SampleCodeinsecure-libb)
+			runner("insecure-lib", testutils.SampleCodeInsecureLibb)
+		})
+
+		It("should detect weak uses of block cipher modes", func() {
+			runner("weak-cipher-mode", testutils.SampleCodeWeakCipherMode)
 		})
 
 		It("should find insecure tls settings", func() {
-			runner("bad-tls", testutils.SampleCodebad-tls)
+			runner("bad-tls", testutils.SampleCodeBadTls)
 		})
 
 		It("should detect weak creation of weak rsa keys", func() {
-			runner("min-key-rsa", testutils.SampleCodemin-key-rsa)
+			runner("min-key-rsa", testutils.SampleCodeMinKeyRsa)
+		})
+
+		It("should detect passwords hashed with a fast hash function", func() {
+			runner("weak-password-hash", testutils.SampleCodeWeakPasswordHash)
+		})
+
+		It("should detect weak parameters of key derivation functions", func() {
+			runner("weak-password-hash", testutils.SampleCodeWeakKDF)
+		})
+
+		It("should read the thresholds of key derivation functions from the configuration", func() {
+			_, err := config.ReadFrom(strings.NewReader(`{"weak-password-hash": {"pbkdf2_iterations": 4096}}`))
+			Expect(err).ShouldNot(HaveOccurred())
+			runner("weak-password-hash", []testutils.CodeSample{{Code: testutils.SampleCodeWeakKDF[4].Code, Errors: 0}})
+		})
+
+		It("should read the credential pattern of the password hashes from the configuration", func() {
+			_, err := config.ReadFrom(strings.NewReader(`{"hardcreds": {"pattern": "(?i)token"}, "weak-password-hash": {"bcrypt_cost": "12"}}`))
+			Expect(err).ShouldNot(HaveOccurred())
+			runner("weak-password-hash", []testutils.CodeSample{{Code: testutils.SampleCodeWeakPasswordHash[0].Code, Errors: 0}})
 		})
 
 		It("should find non cryptographically secure random number sources", func() {
-			runner("insecure-rand", testutils.SampleCodeinsecure-rand)
+			runner("insecure-rand", testutils.SampleCodeInsecureRand)
+		})
+
+		It("should detect bad TLS settings held in variables with the SSA form", func() {
+			runner("bad-tls", append(testutils.SampleCodeBadTls, testutils.SampleCodeBadTlsSSA...), option{name: gosec.SSA, value: "enabled"})
+		})
+
+		It("should detect weak RSA keys sized by variables with the SSA form", func() {
+			runner("min-key-rsa", append(testutils.SampleCodeMinKeyRsa, testutils.SampleCodeMinKeyRsaSSA...), option{name: gosec.SSA, value: "enabled"})
 		})
 
 		It("should detect blacklisted imports - MD5", func() {
-			runner("blacklist-md5", testutils.SampleCodeblacklist-md5)
+			runner("blacklist-md5", testutils.SampleCodeBlacklistMd5)
 		})
 
 		It("should detect blacklisted imports - DES", func() {
-			runner("blacklist-des", testutils.SampleCodeblacklist-des)
+			runner("blacklist-des", testutils.SampleCodeBlacklistDes)
 		})
 
 		It("should detect blacklisted imports - RC4", func() {
-			runner("blacklist-rc4", testutils.SampleCodeblacklist-rc4)
+			runner("blacklist-rc4", testutils.SampleCodeBlacklistRc4)
 		})
 
 		It("should detect blacklisted imports - CGI (httpoxy)", func() {
-			runner("blacklist-http-cgi", testutils.SampleCodeblacklist-http-cgi)
+			runner("blacklist-http-cgi", testutils.SampleCodeBlacklistHttpCgi)
 		})
 		It("should detect blacklisted imports - SHA1", func() {
-			runner("blacklist-sha1", testutils.SampleCodeblacklist-sha1)
+			runner("blacklist-sha1", testutils.SampleCodeBlacklistSha1)
 		})
 
 	})
 
+	Context("report the flows of user input found by the taint analysis", func() {
+		It("should report a proven flow with a high confidence and a heuristic match with a low one", func() {
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "taint-http")).Builders())
+			for n, confidence := range map[int]gosec.Score{0: gosec.Low, 2: gosec.High} {
+				analyzer.Reset()
+				pkg := testutils.NewTestPackage()
+				defer pkg.Close()
+				pkg.AddFile("ssrf.go", testutils.SampleCodeTaintHttp[n].Code[0])
+				Expect(pkg.Write()).ShouldNot(HaveOccurred())
+				Expect(analyzer.Process(buildTags, pkg.Path)).ShouldNot(HaveOccurred())
+				issues, _, _, _ := analyzer.Report()
+				Expect(issues).Should(HaveLen(1))
+				Expect(issues[0].RuleID).Should(Equal("taint-http"))
+				Expect(issues[0].Confidence).Should(Equal(confidence))
+				Expect(len(issues[0].Trace) > 0).Should(Equal(confidence == gosec.High))
+			}
+		})
+
+		It("should ask for an http.Server with timeouts instead of the package-level serve functions", func() {
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "slowloris")).Builders())
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			pkg.AddFile("server.go", testutils.SampleCodeSlowloris[0].Code[0])
+			pkg.AddFile("literal.go", strings.Replace(testutils.SampleCodeSlowloris[1].Code[0], "func main()", "func serve()", 1))
+			Expect(pkg.Write()).ShouldNot(HaveOccurred())
+			Expect(analyzer.Process(buildTags, pkg.Path)).ShouldNot(HaveOccurred())
+			issues, _, _, _ := analyzer.Report()
+			Expect(issues).Should(HaveLen(2))
+			messages := []string{issues[0].What, issues[1].What}
+			Expect(messages).Should(ConsistOf(
+				"Use of net/http ListenAndServe function that has no support for setting timeouts, construct an http.Server with a ReadHeaderTimeout instead",
+				"Potential Slowloris attack because ReadHeaderTimeout is not configured in the http.Server",
+			))
+		})
+
+		It("should report a call with a tainted and an untainted argument once, with its flow", func() {
+			analyzer.LoadRules(rules.Generate(rules.NewRuleFilter(false, "cmd-exec")).Builders())
+			pkg := testutils.NewTestPackage()
+			defer pkg.Close()
+			samples := testutils.SampleCodeCmdExec
+			pkg.AddFile("exec.go", samples[len(samples)-1].Code[0])
+			Expect(pkg.Write()).ShouldNot(HaveOccurred())
+			Expect(analyzer.Process(buildTags, pkg.Path)).ShouldNot(HaveOccurred())
+			issues, _, _, _ := analyzer.Report()
+			Expect(issues).Should(HaveLen(1))
+			Expect(issues[0].RuleID).Should(Equal("cmd-exec"))
+			Expect(issues[0].Severity).Should(Equal(gosec.High))
+			Expect(issues[0].Confidence).Should(Equal(gosec.High))
+			Expect(issues[0].Trace).ShouldNot(BeEmpty())
+		})
+	})
+
 })
@@ -0,0 +1,161 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"github.com/securego/gosec"
+)
+
+type slowloris struct {
+	gosec.MetaData
+	timeouts map[string]bool
+}
+
+// ID returns the identifier for this rule
+func (s *slowloris) ID() string {
+	return s.MetaData.ID
+}
+
+// fieldValue is a value given to a timeout of a server
+type fieldValue struct {
+	name  string
+	value ast.Expr
+}
+
+// isZero checks whether an expression is the constant zero
+func isZero(expr ast.Expr, c *gosec.Context) bool {
+	tv, ok := c.Info.Types[expr]
+	if !ok || tv.Value == nil {
+		return false
+	}
+	zero, exact := constant.Int64Val(constant.ToInt(tv.Value))
+	return exact && zero == 0
+}
+
+// serverVar returns the variable a server literal is assigned to
+func serverVar(lit *ast.CompositeLit, body *ast.BlockStmt, c *gosec.Context) types.Object {
+	isLit := func(expr ast.Expr) bool {
+		if unary, ok := expr.(*ast.UnaryExpr); ok {
+			expr = unary.X
+		}
+		return expr == lit
+	}
+	var obj types.Object
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, rhs := range node.Rhs {
+				if len(node.Lhs) != len(node.Rhs) || !isLit(rhs) {
+					continue
+				}
+				if ident, ok := node.Lhs[i].(*ast.Ident); ok {
+					obj = c.Info.ObjectOf(ident)
+				}
+			}
+		case *ast.ValueSpec:
+			for i, value := range node.Values {
+				if isLit(value) && len(node.Names) == len(node.Values) {
+					obj = c.Info.ObjectOf(node.Names[i])
+				}
+			}
+		}
+		return obj == nil
+	})
+	return obj
+}
+
+// timeoutValues returns the values given to the timeouts of a server, in
+// the literal and in the following assignments of the same function
+func (s *slowloris) timeoutValues(lit *ast.CompositeLit, c *gosec.Context) []fieldValue {
+	var values []fieldValue
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && s.timeouts[key.Name] {
+				values = append(values, fieldValue{key.Name, kv.Value})
+			}
+		}
+	}
+	body := enclosingFunc(lit, c.Root)
+	if body == nil {
+		return values
+	}
+	server := serverVar(lit, body, c)
+	if server == nil {
+		return values
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || assign.Pos() < lit.End() || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, lhs := range assign.Lhs {
+			selector, ok := lhs.(*ast.SelectorExpr)
+			if !ok || !s.timeouts[selector.Sel.Name] {
+				continue
+			}
+			if ident, ok := selector.X.(*ast.Ident); ok && c.Info.ObjectOf(ident) == server {
+				values = append(values, fieldValue{selector.Sel.Name, assign.Rhs[i]})
+			}
+		}
+		return true
+	})
+	return values
+}
+
+// Match inspects the package-level functions of net/http starting a server
+// without timeouts, and the http.Server literals whose read timeouts are
+// unset or zero
+func (s *slowloris) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
+	if call, ok := gosec.MatchCallByPackage(n, c, "net/http", "ListenAndServe", "ListenAndServeTLS", "Serve", "ServeTLS"); ok {
+		_, name, _ := gosec.GetCallInfo(call, c)
+		what := fmt.Sprintf("Use of net/http %s function that has no support for setting timeouts, construct an http.Server with a ReadHeaderTimeout instead", name)
+		return gosec.NewIssue(c, n, s.ID(), what, s.Severity, s.Confidence), nil
+	}
+	lit := gosec.MatchCompLit(n, c, "net/http.Server")
+	if lit == nil {
+		return nil, nil
+	}
+	// the last value given to a timeout wins
+	timeouts := make(map[string]bool)
+	for _, field := range s.timeoutValues(lit, c) {
+		timeouts[field.name] = !isZero(field.value, c)
+	}
+	for _, set := range timeouts {
+		if set {
+			return nil, nil
+		}
+	}
+	return gosec.NewIssue(c, n, s.ID(), s.What, s.Severity, s.Confidence), nil
+}
+
+// NewSlowloris creates a rule which detects the HTTP servers without a
+// timeout on reading the requests
+func NewSlowloris(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
+	return &slowloris{
+		timeouts: map[string]bool{
+			"ReadHeaderTimeout": true,
+			"ReadTimeout":       true,
+		},
+		MetaData: gosec.MetaData{
+			ID:         id,
+			Severity:   gosec.Medium,
+			Confidence: gosec.Low,
+			What:       "Potential Slowloris attack because ReadHeaderTimeout is not configured in the http.Server",
+		},
+	}, []ast.Node{(*ast.CallExpr)(nil), (*ast.CompositeLit)(nil)}
+}
@@ -32,12 +32,20 @@ func (r *ssrf) ResolveVar(n *ast.CallExpr, c *gosec.Context) bool {
 	return false
 }
 
-// Match inspects AST nodes to determine if certain net/http methods are called with variable input
+// Match inspects AST nodes to determine if certain net/http methods are called with variable input.
+// The url is reported with a high confidence when the taint analysis proves that it holds user input.
 func (r *ssrf) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
 	// Call expression is using http package directly
 	if node := r.ContainsCallExpr(n, c, false); node != nil {
+		if len(node.Args) > 0 {
+			if trace, ok := c.Tainted[node.Args[0]]; ok {
+				issue := gosec.NewIssue(c, n, r.ID(), "HTTP request made with user input as url", r.Severity, r.Confidence)
+				issue.Trace = trace
+				return issue, nil
+			}
+		}
 		if r.ResolveVar(node, c) {
-			return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, r.Confidence), nil
+			return gosec.NewIssue(c, n, r.ID(), r.What, r.Severity, gosec.Low), nil
 		}
 	}
 	return nil, nil
@@ -51,7 +59,7 @@ func NewSSRFCheck(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
 			ID:         id,
 			What:       "Potential HTTP request made with variable url",
 			Severity:   gosec.Medium,
-			Confidence: gosec.Medium,
+			Confidence: gosec.High,
 		},
 	}
 	rule.AddAll("net/http", "Do", "Get", "Head", "Post", "PostForm", "RoundTrip")
@@ -39,13 +39,23 @@ func (r *subprocess) ID() string {
 // is unsafe. For example:
 //
 // syscall.Exec("echo", "foobar" + tainted)
+//
+// The arguments proven by the taint analysis to hold user input are
+// reported with a high confidence, the other variables with a low one.
 func (r *subprocess) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
 	if node := r.ContainsCallExpr(n, c, false); node != nil {
+		for _, arg := range node.Args {
+			if trace, ok := c.Tainted[arg]; ok {
+				issue := gosec.NewIssue(c, n, r.ID(), "Subprocess launched with user input", r.Severity, gosec.High)
+				issue.Trace = trace
+				return issue, nil
+			}
+		}
 		for _, arg := range node.Args {
 			if ident, ok := arg.(*ast.Ident); ok {
 				obj := c.Info.ObjectOf(ident)
 				if _, ok := obj.(*types.Var); ok && !gosec.TryResolve(ident, c) {
-					return gosec.NewIssue(c, n, r.ID(), "Subprocess launched with variable", gosec.Medium, gosec.High), nil
+					return gosec.NewIssue(c, n, r.ID(), "Subprocess launched with variable", gosec.Medium, gosec.Low), nil
 				}
 			}
 		}
@@ -56,7 +66,7 @@ func (r *subprocess) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, error) {
 
 // NewSubproc detects cases where we are forking out to an external process
 func NewSubproc(id string, conf gosec.Config) (gosec.Rule, []ast.Node) {
-	rule := &subprocess{gosec.MetaData{ID: id}, gosec.NewCallList()}
+	rule := &subprocess{gosec.MetaData{ID: id, Severity: gosec.High}, gosec.NewCallList()}
 	rule.Add("os/exec", "Command")
 	rule.Add("os/exec", "CommandContext")
 	rule.Add("syscall", "Exec")
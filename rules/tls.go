@@ -19,8 +19,11 @@ package rules
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
+	"go/types"
 
 	"github.com/securego/gosec"
+	"golang.org/x/tools/go/ssa"
 )
 
 type insecureConfigTLS struct {
@@ -61,6 +64,11 @@ func (t *insecureConfigTLS) processTLSCipherSuites(n ast.Node, c *gosec.Context)
 
 func (t *insecureConfigTLS) processTLSConfVal(n *ast.KeyValueExpr, c *gosec.Context) *gosec.Issue {
 	if ident, ok := n.Key.(*ast.Ident); ok {
+		// the values of the settings are resolved by MatchSSA when the
+		// SSA form of the package is available
+		if c.SSA != nil && ident.Name != "CipherSuites" {
+			return nil
+		}
 		switch ident.Name {
 
 		case "InsecureSkipVerify":
@@ -128,3 +136,79 @@ func (t *insecureConfigTLS) Match(n ast.Node, c *gosec.Context) (*gosec.Issue, e
 	}
 	return nil, nil
 }
+
+// Instructions returns the SSA instructions checked by the rule
+func (t *insecureConfigTLS) Instructions() []ssa.Instruction {
+	return []ssa.Instruction{(*ssa.Store)(nil)}
+}
+
+// MatchSSA checks the values stored to the fields of a TLS configuration,
+// either in a composite literal or by an assignment. The values held in
+// variables, struct fields and package variables are resolved.
+func (t *insecureConfigTLS) MatchSSA(instr ssa.Instruction, c *gosec.Context) (*gosec.Issue, error) {
+	store := instr.(*ssa.Store)
+	field, ok := store.Addr.(*ssa.FieldAddr)
+	if !ok {
+		return nil, nil
+	}
+	ptr, ok := field.X.Type().Underlying().(*types.Pointer)
+	if !ok || ptr.Elem().String() != t.requiredType {
+		return nil, nil
+	}
+	var node ast.Node
+	for _, n := range gosec.GetNodePath(store.Pos(), c) {
+		if _, ok := n.(*ast.KeyValueExpr); ok {
+			node = n
+			break
+		}
+		if _, ok := n.(*ast.AssignStmt); ok {
+			node = n
+			break
+		}
+	}
+	if node == nil {
+		return nil, nil
+	}
+
+	value := gosec.ConstValue(store.Val)
+	switch ptr.Elem().Underlying().(*types.Struct).Field(field.Field).Name() {
+	case "InsecureSkipVerify":
+		if value == nil || value.Kind() != constant.Bool {
+			return gosec.NewIssue(c, node, t.ID(), "TLS InsecureSkipVerify may be true.", gosec.High, gosec.Low), nil
+		}
+		if constant.BoolVal(value) {
+			return gosec.NewIssue(c, node, t.ID(), "TLS InsecureSkipVerify set true.", gosec.High, gosec.High), nil
+		}
+
+	case "PreferServerCipherSuites":
+		if value == nil || value.Kind() != constant.Bool {
+			return gosec.NewIssue(c, node, t.ID(), "TLS PreferServerCipherSuites may be false.", gosec.Medium, gosec.Low), nil
+		}
+		if !constant.BoolVal(value) {
+			return gosec.NewIssue(c, node, t.ID(), "TLS PreferServerCipherSuites set false.", gosec.Medium, gosec.High), nil
+		}
+
+	case "MinVersion":
+		if version, ok := tlsVersion(value); !ok {
+			return gosec.NewIssue(c, node, t.ID(), "TLS MinVersion may be too low.", gosec.High, gosec.Low), nil
+		} else if version < t.MinVersion {
+			return gosec.NewIssue(c, node, t.ID(), "TLS MinVersion too low.", gosec.High, gosec.High), nil
+		}
+
+	case "MaxVersion":
+		if version, ok := tlsVersion(value); !ok {
+			return gosec.NewIssue(c, node, t.ID(), "TLS MaxVersion may be too low.", gosec.High, gosec.Low), nil
+		} else if version < t.MaxVersion {
+			return gosec.NewIssue(c, node, t.ID(), "TLS MaxVersion too low.", gosec.High, gosec.High), nil
+		}
+	}
+	return nil, nil
+}
+
+func tlsVersion(value constant.Value) (int16, bool) {
+	if value == nil || value.Kind() != constant.Int {
+		return 0, false
+	}
+	version, ok := constant.Int64Val(value)
+	return int16(version), ok
+}
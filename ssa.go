@@ -0,0 +1,371 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"log"
+	"path"
+	"reflect"
+	"sort"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// SSARule is implemented by the rules which also match the instructions of
+// the SSA form of the functions. The SSA rules are run when the SSA analysis
+// is enabled, in which case the Context holds the SSA form of the package.
+type SSARule interface {
+	Rule
+	Instructions() []ssa.Instruction
+	MatchSSA(ssa.Instruction, *Context) (*Issue, error)
+}
+
+// A SSARuleSet maps lists of rules to the type of SSA instruction they
+// should be run on.
+type SSARuleSet map[reflect.Type][]SSARule
+
+// Register adds a trigger for the supplied rule for the specified
+// SSA instructions.
+func (r SSARuleSet) Register(rule SSARule, instrs ...ssa.Instruction) {
+	for _, instr := range instrs {
+		t := reflect.TypeOf(instr)
+		r[t] = append(r[t], rule)
+	}
+}
+
+// RegisteredFor will return all rules that are registered for a
+// specified SSA instruction.
+func (r SSARuleSet) RegisteredFor(instr ssa.Instruction) []SSARule {
+	return r[reflect.TypeOf(instr)]
+}
+
+// buildSSA builds the SSA form of the scanned packages. The packages which
+// do not type check, or import a package which does not, are left out, as
+// well as the packages using types the SSA builder does not support.
+func buildSSA(program *loader.Program, logger *log.Logger) map[*types.Package]*ssa.Package {
+	packages := make(map[*types.Package]*ssa.Package)
+	prog := ssautil.CreateProgram(program, 0)
+	for _, info := range program.Created {
+		if pkg := prog.Package(info.Pkg); pkg != nil {
+			if err := buildSSAPackage(pkg); err != nil {
+				logger.Printf("Unable to build the SSA form of %s: %v", info.Pkg.Path(), err)
+				// the builder may have panicked holding the locks of its
+				// program, so the other packages are built by a new one
+				prog = ssautil.CreateProgram(program, 0)
+				continue
+			}
+			packages[info.Pkg] = pkg
+		}
+	}
+	return packages
+}
+
+func buildSSAPackage(pkg *ssa.Package) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	pkg.Build()
+	return nil
+}
+
+// ssaFunctions returns the functions and methods declared by a package,
+// including its function literals and initializer, in a stable order
+func ssaFunctions(pkg *ssa.Package) []*ssa.Function {
+	var funcs []*ssa.Function
+	seen := make(map[*ssa.Function]bool)
+	var add func(fn *ssa.Function)
+	add = func(fn *ssa.Function) {
+		if fn == nil || seen[fn] || fn.Pkg != pkg || fn.Synthetic != "" && fn.Name() != "init" {
+			return
+		}
+		seen[fn] = true
+		funcs = append(funcs, fn)
+		for _, anon := range fn.AnonFuncs {
+			add(anon)
+		}
+		// the init functions declared in the files are only called by
+		// the package initializer
+		if fn == pkg.Func("init") {
+			for _, block := range fn.Blocks {
+				for _, instr := range block.Instrs {
+					if call, ok := instr.(*ssa.Call); ok {
+						add(call.Call.StaticCallee())
+					}
+				}
+			}
+		}
+	}
+	names := make([]string, 0, len(pkg.Members))
+	for name := range pkg.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch member := pkg.Members[name].(type) {
+		case *ssa.Function:
+			add(member)
+		case *ssa.Type:
+			for _, t := range []types.Type{member.Type(), types.NewPointer(member.Type())} {
+				methods := pkg.Prog.MethodSets.MethodSet(t)
+				for i := 0; i < methods.Len(); i++ {
+					add(pkg.Prog.MethodValue(methods.At(i)))
+				}
+			}
+		}
+	}
+	return funcs
+}
+
+// checkSSA runs the SSA rules over the functions of the package being
+// checked. The issues are kept until the walk of the files reaches the
+// innermost node of their instruction, so that the #nosec annotations
+// apply to them. If the SSA form cannot be checked, the rules fall back on
+// the AST.
+func (gosec *Analyzer) checkSSA() {
+	defer func() {
+		if r := recover(); r != nil {
+			gosec.logger.Printf("Unable to check the SSA form of %s: %v", gosec.context.Pkg.Path(), r)
+			gosec.context.SSA = nil
+			gosec.pending = make(map[ast.Node][]*Issue)
+		}
+	}()
+	for _, fn := range ssaFunctions(gosec.context.SSA) {
+		for _, block := range fn.Blocks {
+			for _, instr := range block.Instrs {
+				for _, rule := range gosec.ssaRuleset.RegisteredFor(instr) {
+					issue, err := rule.MatchSSA(instr, gosec.context)
+					if err != nil {
+						file := gosec.context.FileSet.Position(instr.Pos())
+						gosec.logger.Printf("Rule error: %v => %s (%s:%d)\n", reflect.TypeOf(rule), err, path.Base(file.Filename), file.Line)
+					}
+					if issue == nil {
+						continue
+					}
+					if nodes := GetNodePath(instr.Pos(), gosec.context); len(nodes) > 0 {
+						gosec.pending[nodes[0]] = append(gosec.pending[nodes[0]], issue)
+					} else {
+						gosec.addIssue(issue)
+					}
+				}
+			}
+		}
+	}
+}
+
+// GetNodePath returns the nodes of the package being checked enclosing a
+// position, starting with the innermost one. It is used to report the
+// issues found on SSA instructions.
+func GetNodePath(pos token.Pos, ctx *Context) []ast.Node {
+	for _, file := range ctx.PkgFiles {
+		if file.Pos() <= pos && pos <= file.End() {
+			path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+			return path
+		}
+	}
+	return nil
+}
+
+// ConstValue resolves the constant held by an SSA value. It follows the
+// conversions, the operations on constants, the phi nodes merging a single
+// constant, and the loads of the local variables, struct fields and
+// package variables which are stored a single constant. It returns nil if
+// the value is not constant.
+func ConstValue(v ssa.Value) constant.Value {
+	return constValue(v, make(map[ssa.Value]bool))
+}
+
+func constValue(v ssa.Value, seen map[ssa.Value]bool) constant.Value {
+	if v == nil || seen[v] {
+		return nil
+	}
+	seen[v] = true
+	defer delete(seen, v)
+
+	switch v := v.(type) {
+	case *ssa.Const:
+		return v.Value
+	case *ssa.Convert:
+		return constValue(v.X, seen)
+	case *ssa.ChangeType:
+		return constValue(v.X, seen)
+	case *ssa.UnOp:
+		if v.Op == token.MUL {
+			return storedValue(v.X, seen)
+		}
+		x := constValue(v.X, seen)
+		if x == nil || v.Op == token.ARROW {
+			return nil
+		}
+		return constant.UnaryOp(v.Op, x, 0)
+	case *ssa.BinOp:
+		x, y := constValue(v.X, seen), constValue(v.Y, seen)
+		if x == nil || y == nil {
+			return nil
+		}
+		return binaryOp(v, x, y)
+	case *ssa.Phi:
+		var value constant.Value
+		for _, edge := range v.Edges {
+			if edge == v {
+				continue
+			}
+			c := constValue(edge, seen)
+			if c == nil || value != nil && (c.Kind() != value.Kind() || !constant.Compare(c, token.EQL, value)) {
+				return nil
+			}
+			value = c
+		}
+		return value
+	}
+	return nil
+}
+
+func binaryOp(v *ssa.BinOp, x, y constant.Value) constant.Value {
+	switch v.Op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		if x.Kind() != y.Kind() {
+			return nil
+		}
+		return constant.MakeBool(constant.Compare(x, v.Op, y))
+	case token.SHL, token.SHR:
+		if s, ok := constant.Uint64Val(y); ok && x.Kind() == constant.Int {
+			return constant.Shift(x, v.Op, uint(s))
+		}
+		return nil
+	case token.QUO, token.REM:
+		if constant.Sign(y) == 0 {
+			return nil
+		}
+	}
+	if x.Kind() != y.Kind() && (x.Kind() == constant.String || y.Kind() == constant.String || x.Kind() == constant.Bool || y.Kind() == constant.Bool) {
+		return nil
+	}
+	op := v.Op
+	if basic, ok := v.Type().Underlying().(*types.Basic); ok && op == token.QUO && basic.Info()&types.IsInteger != 0 {
+		op = token.QUO_ASSIGN // integer division
+	}
+	return constant.BinaryOp(x, op, y)
+}
+
+// storedValue resolves the constant loaded from an address, if a single
+// constant is stored to it. The addresses which escape, e.g. passed to a
+// function or stored in a pointer, may be written elsewhere and are not
+// resolved.
+func storedValue(addr ssa.Value, seen map[ssa.Value]bool) constant.Value {
+	var stores []*ssa.Store
+	switch addr := addr.(type) {
+	case *ssa.Alloc:
+		if !loadedOrStored(addr, referrers(addr)) {
+			return nil
+		}
+		stores = storesTo(addr)
+	case *ssa.FieldAddr:
+		// the field may be addressed by several instructions, and the
+		// whole struct must not escape either
+		for _, ref := range referrers(addr.X) {
+			switch ref := ref.(type) {
+			case *ssa.FieldAddr:
+				if ref.Field != addr.Field {
+					continue
+				}
+				if !loadedOrStored(ref, referrers(ref)) {
+					return nil
+				}
+				stores = append(stores, storesTo(ref)...)
+			case *ssa.UnOp, *ssa.DebugRef:
+				if !loadedOrStored(addr.X, []ssa.Instruction{ref}) {
+					return nil
+				}
+			default:
+				return nil
+			}
+		}
+	case *ssa.Global:
+		// the referrers of the globals are not recorded
+		var refs []ssa.Instruction
+		for _, fn := range ssaFunctions(addr.Pkg) {
+			for _, block := range fn.Blocks {
+				for _, instr := range block.Instrs {
+					for _, operand := range instr.Operands(nil) {
+						if *operand == addr {
+							refs = append(refs, instr)
+							break
+						}
+					}
+				}
+			}
+		}
+		if !loadedOrStored(addr, refs) {
+			return nil
+		}
+		for _, ref := range refs {
+			if store, ok := ref.(*ssa.Store); ok {
+				stores = append(stores, store)
+			}
+		}
+	}
+	if len(stores) != 1 {
+		return nil
+	}
+	return constValue(stores[0].Val, seen)
+}
+
+func referrers(v ssa.Value) []ssa.Instruction {
+	if refs := v.Referrers(); refs != nil {
+		return *refs
+	}
+	return nil
+}
+
+// loadedOrStored checks whether the instructions referring to an address
+// only load from it or store to it
+func loadedOrStored(addr ssa.Value, refs []ssa.Instruction) bool {
+	for _, ref := range refs {
+		switch ref := ref.(type) {
+		case *ssa.Store:
+			if ref.Addr != addr {
+				return false
+			}
+		case *ssa.UnOp:
+			if ref.Op != token.MUL {
+				return false
+			}
+		case *ssa.DebugRef:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func storesTo(addr ssa.Value) []*ssa.Store {
+	var stores []*ssa.Store
+	if refs := addr.Referrers(); refs != nil {
+		for _, ref := range *refs {
+			if store, ok := ref.(*ssa.Store); ok && store.Addr == addr {
+				stores = append(stores, store)
+			}
+		}
+	}
+	return stores
+}
@@ -0,0 +1,247 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+)
+
+// taintSource describes where user input comes from, resolved through the
+// type information rather than the names used in the code:
+//   - the parameters of a type, or of a pointer to it. All the types of the
+//     package are matched if no type is given.
+//   - the results of functions, or of the methods of a receiver type. The
+//     input is written to the arguments at the indexes given with args
+//     instead, e.g. for flag.StringVar.
+//   - package variables, e.g. os.Args
+//   - the request parameter of the RPC handlers, recognised by the method
+//     of the generated messages, e.g. ProtoMessage for gRPC. A handler is a
+//     method taking a context.Context and a message.
+type taintSource struct {
+	Package   string   `json:"package"`
+	Type      string   `json:"type"`
+	Receiver  string   `json:"receiver"`
+	Functions []string `json:"functions"`
+	Args      []int    `json:"args"`
+	Variables []string `json:"variables"`
+	Message   string   `json:"message"`
+}
+
+// isType checks whether the source describes the parameters of a type
+func (s *taintSource) isType() bool {
+	return len(s.Functions) == 0 && len(s.Variables) == 0 && s.Message == ""
+}
+
+// taintSanitizer describes the functions of a validation package. When
+// the functions are methods, the constructor of their receiver can be
+// given so that they are recognised even if the package does not type check.
+type taintSanitizer struct {
+	Package     string   `json:"package"`
+	Constructor string   `json:"constructor"`
+	Functions   []string `json:"functions"`
+}
+
+// taintSink describes the functions receiving data which must be validated
+// first, e.g. the methods of a receiver type. The indexes of the checked
+// arguments can be restricted with args.
+type taintSink struct {
+	Package   string   `json:"package"`
+	Receiver  string   `json:"receiver"`
+	Functions []string `json:"functions"`
+	Args      []int    `json:"args"`
+	RuleID    string   `json:"rule_id"`
+	Severity  Score    `json:"severity"`
+}
+
+// taintConfig holds the sources, sanitizers and sinks of the taint analysis
+type taintConfig struct {
+	sources    []*taintSource
+	sanitizers []*taintSanitizer
+	sinks      []*taintSink
+}
+
+func defaultTaintConfig() *taintConfig {
+	return &taintConfig{
+		sources: []*taintSource{
+			{Package: "net/http", Type: "Request"},
+			{Package: "net/url", Type: "Values"},
+			{Package: "net", Type: "Conn"},
+			{Package: "github.com/gin-gonic/gin", Type: "Context"},
+			{Package: "github.com/labstack/echo", Type: "Context"},
+			{Package: "github.com/labstack/echo/v4", Type: "Context"},
+			{Message: "ProtoMessage"},
+			{Package: "os", Functions: []string{"Getenv", "LookupEnv", "Environ"}},
+			{Package: "os", Variables: []string{"Args"}},
+			{Package: "flag", Functions: []string{"String", "Arg", "Args"}},
+			{Package: "flag", Functions: []string{"StringVar"}, Args: []int{0}},
+			{Package: "flag", Receiver: "FlagSet", Functions: []string{"String", "Arg", "Args"}},
+			{Package: "flag", Receiver: "FlagSet", Functions: []string{"StringVar"}, Args: []int{0}},
+			{Package: "net", Functions: []string{"Dial", "DialTimeout"}},
+			{Package: "net", Receiver: "Dialer", Functions: []string{"Dial", "DialContext"}},
+			{Package: "net", Receiver: "Listener", Functions: []string{"Accept"}},
+			{Package: "net", Receiver: "TCPListener", Functions: []string{"Accept", "AcceptTCP"}},
+			{Package: "net", Receiver: "UnixListener", Functions: []string{"Accept", "AcceptUnix"}},
+		},
+		sanitizers: []*taintSanitizer{
+			{Package: "github.com/go-ozzo/ozzo-validation", Functions: []string{"Validate"}},
+			{Package: "gopkg.in/go-playground/validator.v9", Constructor: "New", Functions: []string{"Struct", "Var"}},
+			{Package: "github.com/lib/pq", Functions: []string{"QuoteIdentifier", "QuoteLiteral"}},
+		},
+		sinks: []*taintSink{
+			{Package: "database/sql", Receiver: "DB", Functions: []string{"Query", "QueryRow", "Exec", "Prepare"}, Args: []int{0}, RuleID: "taint-sql", Severity: High},
+			{Package: "database/sql", Receiver: "DB", Functions: []string{"QueryContext", "QueryRowContext", "ExecContext", "PrepareContext"}, Args: []int{1}, RuleID: "taint-sql", Severity: High},
+			{Package: "database/sql", Receiver: "Tx", Functions: []string{"Query", "QueryRow", "Exec", "Prepare"}, Args: []int{0}, RuleID: "taint-sql", Severity: High},
+			{Package: "database/sql", Receiver: "Tx", Functions: []string{"QueryContext", "QueryRowContext", "ExecContext", "PrepareContext"}, Args: []int{1}, RuleID: "taint-sql", Severity: High},
+			{Package: "os/exec", Functions: []string{"Command", "CommandContext"}, RuleID: "taint-cmd-exec", Severity: High},
+			{Package: "os", Functions: []string{"Open", "OpenFile", "Create", "Remove", "RemoveAll", "Mkdir", "MkdirAll"}, Args: []int{0}, RuleID: "taint-file-access", Severity: Medium},
+			{Package: "io/ioutil", Functions: []string{"ReadFile", "WriteFile", "ReadDir"}, Args: []int{0}, RuleID: "taint-file-access", Severity: Medium},
+			{Package: "net/http", Functions: []string{"Get", "Head", "Post", "PostForm"}, Args: []int{0}, RuleID: "taint-http-request", Severity: Medium},
+			{Package: "net/http", Functions: []string{"NewRequest"}, Args: []int{1}, RuleID: "taint-http-request", Severity: Medium},
+		},
+	}
+}
+
+// newTaintConfig adds the sources, sanitizers and sinks declared in the
+// TaintAnalysis section of the configuration to the default ones, e.g.
+//
+//	"TaintAnalysis": {
+//	    "sources": [{"package": "example.com/api/pb"}],
+//	    "sanitizers": [{"package": "example.com/check", "functions": ["Input"]}],
+//	    "sinks": [{"package": "example.com/store", "receiver": "DB", "functions": ["Raw"],
+//	               "args": [0], "rule_id": "taint-store", "severity": "HIGH"}]
+//	}
+func newTaintConfig(conf Config) (*taintConfig, error) {
+	config := defaultTaintConfig()
+	section, ok := conf[TaintAnalysisID]
+	if !ok {
+		return config, nil
+	}
+	data, err := json.Marshal(section)
+	if err != nil {
+		return config, err
+	}
+	var declared struct {
+		Sources    []*taintSource    `json:"sources"`
+		Sanitizers []*taintSanitizer `json:"sanitizers"`
+		Sinks      []json.RawMessage `json:"sinks"`
+	}
+	if err := json.Unmarshal(data, &declared); err != nil {
+		return config, err
+	}
+	for _, source := range declared.Sources {
+		if source.Package == "" && source.Message == "" {
+			return config, fmt.Errorf("taint source without a package")
+		}
+		config.sources = append(config.sources, source)
+	}
+	for _, sanitizer := range declared.Sanitizers {
+		if sanitizer.Package == "" || len(sanitizer.Functions) == 0 {
+			return config, fmt.Errorf("taint sanitizer without a package or functions")
+		}
+		config.sanitizers = append(config.sanitizers, sanitizer)
+	}
+	for _, raw := range declared.Sinks {
+		sink := &taintSink{RuleID: TaintAnalysisID, Severity: Medium}
+		if err := json.Unmarshal(raw, sink); err != nil {
+			return config, err
+		}
+		if sink.Package == "" || len(sink.Functions) == 0 {
+			return config, fmt.Errorf("taint sink without a package or functions")
+		}
+		config.sinks = append(config.sinks, sink)
+	}
+	return config, nil
+}
+
+// isSource checks whether a parameter of the given type holds user input
+func (c *taintConfig) isSource(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	for _, source := range c.sources {
+		if source.isType() && source.Package == named.Obj().Pkg().Path() && (source.Type == "" || source.Type == named.Obj().Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSourceParam checks whether a parameter of a function holds user input,
+// either because of its type or as the request of an RPC handler
+func (c *taintConfig) isSourceParam(sig *types.Signature, param *types.Var) bool {
+	if c.isSource(param.Type()) {
+		return true
+	}
+	if sig == nil || sig.Recv() == nil || sig.Params().Len() != 2 || sig.Params().At(1) != param {
+		return false
+	}
+	if !isNamedType(sig.Params().At(0).Type(), "context", "Context") {
+		return false
+	}
+	for _, source := range c.sources {
+		if source.Message != "" && hasMethod(param.Type(), source.Message) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceFunc returns the source matching a function, if any
+func (c *taintConfig) sourceFunc(pkg, recv, name string) *taintSource {
+	for _, source := range c.sources {
+		if source.Package == pkg && source.Receiver == recv && contains(source.Functions, name) {
+			return source
+		}
+	}
+	return nil
+}
+
+// isSourceVar checks whether a package variable holds user input
+func (c *taintConfig) isSourceVar(v *types.Var) bool {
+	if v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return false
+	}
+	for _, source := range c.sources {
+		if source.Package == v.Pkg().Path() && contains(source.Variables, v.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNamedType(t types.Type, pkg, name string) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == pkg && named.Obj().Name() == name
+}
+
+func hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// sink returns the sink matching a function, if any
+func (c *taintConfig) sink(pkg, recv, name string) *taintSink {
+	for _, sink := range c.sinks {
+		if sink.Package == pkg && sink.Receiver == recv && contains(sink.Functions, name) {
+			return sink
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"go/ast"
+	"go/types"
+	"path/filepath"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// maxSummaryPasses bounds the number of passes over the call graph, which
+// converges after a few passes unless the call chains are very deep
+const maxSummaryPasses = 10
+
+// taintSummary describes how the taint flows through a function. The
+// parameters are identified by their index, counting the receiver first.
+type taintSummary struct {
+	returns []taintLabels              // parameters flowing into each result, or sourceLabel for user input
+	sinks   map[*taintSink]taintLabels // parameters flowing into each sink
+}
+
+func (s *taintSummary) equal(other *taintSummary) bool {
+	if len(s.sinks) != len(other.sinks) || len(s.returns) != len(other.returns) {
+		return false
+	}
+	for sink, params := range s.sinks {
+		if !params.equal(other.sinks[sink]) {
+			return false
+		}
+	}
+	for i := range s.returns {
+		if !s.returns[i].equal(other.returns[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+type summaryFunc struct {
+	info *types.Info
+	decl *ast.FuncDecl
+}
+
+// summarizeTaint computes the taint summaries of the functions declared by
+// the packages of the scanned directories. The loader type checks a package
+// imported by another scanned package a second time, so the imported copy
+// is summarized as well to follow the calls across the packages, including
+// the packages whose results are cached, which are loaded as imports only.
+func summarizeTaint(program *loader.Program, dirs map[string]bool, config *taintConfig) map[*types.Func]*taintSummary {
+
+	funcs := make(map[*types.Func]*summaryFunc)
+	for _, pkg := range program.AllPackages {
+		for _, file := range pkg.Files {
+			dir, err := filepath.Abs(filepath.Dir(program.Fset.File(file.Pos()).Name()))
+			if err != nil || !dirs[dir] {
+				break
+			}
+			for _, decl := range file.Decls {
+				if fnDecl, ok := decl.(*ast.FuncDecl); ok && fnDecl.Body != nil {
+					if fn, ok := pkg.Info.Defs[fnDecl.Name].(*types.Func); ok {
+						funcs[fn] = &summaryFunc{info: &pkg.Info, decl: fnDecl}
+					}
+				}
+			}
+		}
+	}
+
+	return summarizeFuncs(funcs, config, nil)
+}
+
+// summarizeFuncs computes the summaries of the functions, passing over them
+// until the summaries do not change. The calls to other functions are
+// followed using their known summaries.
+func summarizeFuncs(funcs map[*types.Func]*summaryFunc, config *taintConfig, known map[*types.Func]*taintSummary) map[*types.Func]*taintSummary {
+	summaries := make(map[*types.Func]*taintSummary, len(funcs)+len(known))
+	for fn, summary := range known {
+		summaries[fn] = summary
+	}
+	for pass := 0; pass < maxSummaryPasses; pass++ {
+		changed := false
+		next := make(map[*types.Func]*taintSummary, len(funcs)+len(known))
+		for fn, summary := range known {
+			next[fn] = summary
+		}
+		for fn, def := range funcs {
+			f := analyzeFunction(def.info, def.decl, config, summaries, nil, nil)
+			summary := &taintSummary{returns: f.returns, sinks: f.sinks}
+			if previous, ok := summaries[fn]; !ok || !previous.equal(summary) {
+				changed = true
+			}
+			next[fn] = summary
+		}
+		summaries = next
+		if !changed {
+			break
+		}
+	}
+	return summaries
+}
+
+// TaintFact is the taint summary of a function in a form which can be
+// encoded, for the drivers passing the summaries between the packages. The
+// parameters are identified by their index, counting the receiver first,
+// and the user input by -1. The traces of the flows are left out.
+type TaintFact struct {
+	// Returns are the parameters flowing into each result
+	Returns [][]int
+	// Sinks are the parameters flowing into the sinks, sorted by sink
+	Sinks []TaintSinkFlow
+}
+
+// TaintSinkFlow gives the parameters flowing into a sink, identified by its
+// index in the configuration of the taint analysis
+type TaintSinkFlow struct {
+	Sink   int
+	Params []int
+}
+
+// fact converts a summary into a fact
+func (c *taintConfig) fact(summary *taintSummary) *TaintFact {
+	fact := &TaintFact{}
+	for _, labels := range summary.returns {
+		fact.Returns = append(fact.Returns, labels.sorted())
+	}
+	for i, sink := range c.sinks {
+		if labels, ok := summary.sinks[sink]; ok {
+			fact.Sinks = append(fact.Sinks, TaintSinkFlow{Sink: i, Params: labels.sorted()})
+		}
+	}
+	return fact
+}
+
+// flows checks whether user input flows through the function, into its
+// results or into a sink
+func (f *TaintFact) flows() bool {
+	for _, params := range f.Returns {
+		if len(params) > 0 {
+			return true
+		}
+	}
+	return len(f.Sinks) > 0
+}
+
+// summary converts a fact computed with the same configuration into a
+// summary
+func (c *taintConfig) summary(fact *TaintFact) *taintSummary {
+	labelsOf := func(params []int) taintLabels {
+		if len(params) == 0 {
+			return nil
+		}
+		labels := make(taintLabels, len(params))
+		for _, param := range params {
+			labels[param] = nil
+		}
+		return labels
+	}
+	summary := &taintSummary{sinks: make(map[*taintSink]taintLabels)}
+	for _, params := range fact.Returns {
+		summary.returns = append(summary.returns, labelsOf(params))
+	}
+	for _, flow := range fact.Sinks {
+		if flow.Sink >= 0 && flow.Sink < len(c.sinks) {
+			summary.sinks[c.sinks[flow.Sink]] = labelsOf(flow.Params)
+		}
+	}
+	return summary
+}
@@ -0,0 +1,203 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gosec
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+)
+
+// elementPath is the path of the elements of a slice, an array or a map,
+// which are not told apart by their index or key
+const elementPath = "[]"
+
+// taintValue holds the labels of a value as a whole, and the labels of its
+// fields and elements separately, so that a tainted field does not taint
+// the other fields of a struct.
+type taintValue struct {
+	labels taintLabels
+	fields map[string]*taintValue
+}
+
+// names returns the fields of the value in a stable order
+func (v *taintValue) names() []string {
+	names := make([]string, 0, len(v.fields))
+	for name := range v.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flatten returns the labels of the value and of all of its parts
+func (v *taintValue) flatten() taintLabels {
+	if v == nil {
+		return nil
+	}
+	labels := v.labels
+	for _, name := range v.names() {
+		labels = labels.union(v.fields[name].flatten())
+	}
+	return labels
+}
+
+func (v *taintValue) empty() bool {
+	return len(v.flatten()) == 0
+}
+
+// merge returns the union of two values, allocating only when needed
+func (v *taintValue) merge(other *taintValue) *taintValue {
+	if other.empty() {
+		return v
+	}
+	if v.empty() {
+		return other
+	}
+	merged := &taintValue{labels: v.labels.union(other.labels)}
+	for _, value := range []*taintValue{v, other} {
+		for name, field := range value.fields {
+			if merged.fields == nil {
+				merged.fields = make(map[string]*taintValue)
+			}
+			merged.fields[name] = merged.fields[name].merge(field)
+		}
+	}
+	return merged
+}
+
+// step adds a step to the traces of all the labels of the value
+func (v *taintValue) step(node ast.Node, what string) *taintValue {
+	if v.empty() {
+		return nil
+	}
+	value := &taintValue{labels: v.labels.step(node, what)}
+	for name, field := range v.fields {
+		if field = field.step(node, what); field != nil {
+			if value.fields == nil {
+				value.fields = make(map[string]*taintValue)
+			}
+			value.fields[name] = field
+		}
+	}
+	return value
+}
+
+// taintPlace is the location of a variable, or of one of its fields or
+// elements, e.g. req.User.Name or args[]
+type taintPlace struct {
+	obj  types.Object
+	path []string
+}
+
+func (p taintPlace) field(name string) taintPlace {
+	path := make([]string, len(p.path), len(p.path)+1)
+	copy(path, p.path)
+	return taintPlace{obj: p.obj, path: append(path, name)}
+}
+
+// weak checks whether the place is an element of a collection, which is
+// shared with the other elements
+func (p taintPlace) weak() bool {
+	for _, name := range p.path {
+		if name == elementPath {
+			return true
+		}
+	}
+	return false
+}
+
+// read returns the value at a place, including the labels of the values
+// it is a part of
+func (f *taintFunction) read(p taintPlace) *taintValue {
+	v := f.state[p.obj]
+	var labels taintLabels
+	for _, name := range p.path {
+		if v == nil {
+			break
+		}
+		labels = labels.union(v.labels)
+		v = v.fields[name]
+	}
+	if v == nil {
+		if len(labels) == 0 {
+			return nil
+		}
+		return &taintValue{labels: labels}
+	}
+	return (&taintValue{labels: labels}).merge(v)
+}
+
+// write stores a value at a place. A strong update replaces the value,
+// a weak one adds to it.
+func (f *taintFunction) write(p taintPlace, value *taintValue, strong bool) {
+	if value.empty() {
+		value = nil
+	}
+	if len(p.path) == 0 {
+		if !strong {
+			value = f.state[p.obj].merge(value)
+		}
+		if value == nil {
+			delete(f.state, p.obj)
+		} else {
+			f.state[p.obj] = value
+		}
+		return
+	}
+	if value == nil && f.state[p.obj] == nil {
+		return
+	}
+	// the values along the path are copied as they may be shared
+	root := &taintValue{}
+	if v := f.state[p.obj]; v != nil {
+		root = v.copy()
+	}
+	f.state[p.obj] = root
+	v := root
+	last := len(p.path) - 1
+	for _, name := range p.path[:last] {
+		child := &taintValue{}
+		if v.fields[name] != nil {
+			child = v.fields[name].copy()
+		}
+		if v.fields == nil {
+			v.fields = make(map[string]*taintValue)
+		}
+		v.fields[name] = child
+		v = child
+	}
+	if !strong {
+		value = v.fields[p.path[last]].merge(value)
+	}
+	if value == nil {
+		delete(v.fields, p.path[last])
+		return
+	}
+	if v.fields == nil {
+		v.fields = make(map[string]*taintValue)
+	}
+	v.fields[p.path[last]] = value
+}
+
+// copy returns a shallow copy of the value, whose fields can be replaced
+func (v *taintValue) copy() *taintValue {
+	value := &taintValue{labels: v.labels}
+	if len(v.fields) > 0 {
+		value.fields = make(map[string]*taintValue, len(v.fields))
+		for name, field := range v.fields {
+			value.fields[name] = field
+		}
+	}
+	return value
+}
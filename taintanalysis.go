@@ -1,242 +1,1020 @@
 package gosec
 
 import (
-	_ "fmt"
+	"fmt"
 	"go/ast"
 	"go/token"
-	"path"
-	_ "reflect"
+	"go/types"
+	"sort"
 )
 
-type packageDetails struct {
-	packageName  string
-	constructor  string
-	functionList []string
+// sourceLabel is the label of values derived from user input. The other
+// labels are the indexes of the parameters a value was derived from.
+const sourceLabel = -1
+
+// taintStep is a node through which a tainted value flows
+type taintStep struct {
+	node ast.Node
+	what string
 }
 
-type pkgFunctions struct {
-	constructor  string
-	functionList []string
+// taintTrace is the path followed by a tainted value from its origin. The
+// traces are shared between values, so steps are added to a copy.
+type taintTrace []taintStep
+
+func (t taintTrace) step(node ast.Node, what string) taintTrace {
+	trace := make(taintTrace, len(t), len(t)+1)
+	copy(trace, t)
+	return append(trace, taintStep{node: node, what: what})
 }
 
-type varState struct {
-	tainted   bool
-	used      bool
-	validated bool
+func (t taintTrace) concat(other taintTrace) taintTrace {
+	trace := make(taintTrace, 0, len(t)+len(other))
+	return append(append(trace, t...), other...)
 }
 
-type inputVariables struct {
-	filename      string
-	functionName  string
-	variableName  string
-	variableState varState
-	node 		  ast.Node
-	columnNumber  int
+// taintLabels is the set of origins of a tainted value, with the trace
+// of the first path found from each origin
+type taintLabels map[int]taintTrace
+
+// union returns the labels of both sets, allocating only when needed
+func (l taintLabels) union(other taintLabels) taintLabels {
+	if len(other) == 0 {
+		return l
+	}
+	if len(l) == 0 {
+		return other
+	}
+	labels := make(taintLabels, len(l)+len(other))
+	for label, trace := range other {
+		labels[label] = trace
+	}
+	for label, trace := range l {
+		labels[label] = trace
+	}
+	return labels
 }
 
-type FuncVisitor struct {
-	rhsIdents []string
+// step adds a step to the traces of all the labels
+func (l taintLabels) step(node ast.Node, what string) taintLabels {
+	if len(l) == 0 {
+		return l
+	}
+	labels := make(taintLabels, len(l))
+	for label, trace := range l {
+		labels[label] = trace.step(node, what)
+	}
+	return labels
+}
+
+// sorted returns the labels in a stable order
+func (l taintLabels) sorted() []int {
+	labels := make([]int, 0, len(l))
+	for label := range l {
+		labels = append(labels, label)
+	}
+	sort.Ints(labels)
+	return labels
+}
+
+func (l taintLabels) equal(other taintLabels) bool {
+	if len(l) != len(other) {
+		return false
+	}
+	for label := range l {
+		if _, ok := other[label]; !ok {
+			return false
+		}
+	}
+	return true
 }
 
-type CallExpressions struct {
-	funcCalls []*ast.CallExpr
+// funcName returns the package path, receiver type name and name of a function
+func funcName(fn *types.Func) (string, string, string) {
+	if fn.Pkg() == nil {
+		return "", "", fn.Name()
+	}
+	recv := ""
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		t := sig.Recv().Type()
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		if named, ok := t.(*types.Named); ok {
+			recv = named.Obj().Name()
+		}
+	}
+	return fn.Pkg().Path(), recv, fn.Name()
 }
 
-func (v *FuncVisitor) Visit(n ast.Node) (w ast.Visitor) {
-	switch t := n.(type) {
+// calleeFunc returns the function statically called by a call expression
+func calleeFunc(info *types.Info, call *ast.CallExpr) *types.Func {
+	switch fun := unparen(call.Fun).(type) {
 	case *ast.Ident:
-		v.rhsIdents = append(v.rhsIdents, t.Name)
+		fn, _ := info.Uses[fun].(*types.Func)
+		return fn
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[fun]; ok {
+			fn, _ := sel.Obj().(*types.Func)
+			return fn
+		}
+		fn, _ := info.Uses[fun.Sel].(*types.Func)
+		return fn
 	}
-	return v
+	return nil
 }
 
-func (v *CallExpressions) Visit(n ast.Node) (w ast.Visitor) {
-	switch t := n.(type) {
-	case *ast.CallExpr:
-		v.funcCalls = append(v.funcCalls, t)
+// importedCallee returns the package path and name of a call to a package
+// level function, even if the package could not be type checked
+func importedCallee(info *types.Info, call *ast.CallExpr) (string, string, bool) {
+	if sel, ok := unparen(call.Fun).(*ast.SelectorExpr); ok {
+		if id, ok := sel.X.(*ast.Ident); ok {
+			if pkg, ok := info.Uses[id].(*types.PkgName); ok {
+				return pkg.Imported().Path(), sel.Sel.Name, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
 	}
-	return v
 }
 
-func checkFuncDecl(fnDecl *ast.FuncDecl) (string, bool) {
-	for _, arg := range fnDecl.Type.Params.List {
-		if expr, ok := arg.Type.(*ast.StarExpr); ok {
-			if id, ok := expr.X.(*ast.SelectorExpr); ok {
-				if pkg, ok := id.X.(*ast.Ident); ok {
-					if pkg.Name+"."+id.Sel.Name == "http.Request" {
-						return arg.Names[0].Name, true
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIndex(list []int, i int) bool {
+	for _, item := range list {
+		if item == i {
+			return true
+		}
+	}
+	return false
+}
+
+// taintFunction holds the state of the taint analysis of a function body.
+// Statements are evaluated in order, so a value used before it is
+// validated is still reported.
+type taintFunction struct {
+	info       *types.Info
+	config     *taintConfig
+	summaries  map[*types.Func]*taintSummary
+	state      map[types.Object]*taintValue
+	aliases    map[types.Object]taintPlace
+	closures   map[types.Object]*ast.FuncLit
+	inClosure  map[*ast.FuncLit]bool
+	validators map[types.Object]*taintSanitizer
+	results    []types.Object
+	returns    []taintLabels
+	sinks      map[*taintSink]taintLabels
+	report     func(node ast.Node, sink *taintSink, trace taintTrace)
+	reported   map[ast.Node]map[*taintSink]bool
+	flow       func(arg ast.Expr, trace taintTrace)
+}
+
+// analyzeFunction runs the taint analysis over a function declaration. The
+// parameters holding user input are labelled as a source, the other ones by
+// their index, counting the receiver first. Flows of user input into a sink
+// are passed to report, and the arguments of all the calls holding user
+// input are passed to flow. Both may be nil.
+func analyzeFunction(info *types.Info, decl *ast.FuncDecl, config *taintConfig, summaries map[*types.Func]*taintSummary, report func(node ast.Node, sink *taintSink, trace taintTrace), flow func(arg ast.Expr, trace taintTrace)) *taintFunction {
+	f := &taintFunction{
+		info:       info,
+		config:     config,
+		summaries:  summaries,
+		state:      make(map[types.Object]*taintValue),
+		aliases:    make(map[types.Object]taintPlace),
+		closures:   make(map[types.Object]*ast.FuncLit),
+		inClosure:  make(map[*ast.FuncLit]bool),
+		validators: make(map[types.Object]*taintSanitizer),
+		sinks:      make(map[*taintSink]taintLabels),
+		report:     report,
+		reported:   make(map[ast.Node]map[*taintSink]bool),
+		flow:       flow,
+	}
+	var sig *types.Signature
+	if fn, ok := info.Defs[decl.Name].(*types.Func); ok {
+		sig, _ = fn.Type().(*types.Signature)
+	}
+	index := 0
+	for _, fields := range []*ast.FieldList{decl.Recv, decl.Type.Params} {
+		if fields == nil {
+			continue
+		}
+		for _, field := range fields.List {
+			if len(field.Names) == 0 {
+				index++
+				continue
+			}
+			for _, name := range field.Names {
+				if obj, ok := info.Defs[name].(*types.Var); ok {
+					if config.isSourceParam(sig, obj) {
+						f.state[obj] = &taintValue{labels: taintLabels{sourceLabel: taintTrace{{node: name, what: "user input in parameter " + name.Name}}}}
+					} else {
+						f.state[obj] = &taintValue{labels: taintLabels{index: taintTrace{{node: name, what: "parameter " + name.Name}}}}
 					}
 				}
+				index++
+			}
+		}
+	}
+	f.results = f.resultObjects(decl.Type)
+	f.returns = make([]taintLabels, len(f.results))
+	if decl.Body != nil {
+		f.stmts(decl.Body.List)
+	}
+	return f
+}
+
+func (f *taintFunction) resultObjects(fn *ast.FuncType) []types.Object {
+	var results []types.Object
+	if fn.Results != nil {
+		for _, field := range fn.Results.List {
+			if len(field.Names) == 0 {
+				results = append(results, nil)
+			}
+			for _, name := range field.Names {
+				results = append(results, f.info.Defs[name])
 			}
 		}
 	}
-	return "", false
+	return results
 }
 
-func checkValidateConstructor(assignmentStatement *ast.AssignStmt, validationPackages map[string]*pkgFunctions) (string, string, bool) {
-	if callExpr, ok := assignmentStatement.Rhs[0].(*ast.CallExpr); ok {
-		if selExpr, ok := callExpr.Fun.(*ast.SelectorExpr); ok {
-			if pkg, ok := selExpr.X.(*ast.Ident); ok {
-				if val, ok := validationPackages[pkg.Name]; ok && val.constructor == selExpr.Sel.Name {
-					if lhs, ok := assignmentStatement.Lhs[0].(*ast.Ident); ok {
-						return pkg.Name, lhs.Name, true
-					}
+// closure evaluates the body of a function literal with the labels of its
+// arguments, and returns the labels of its results. The variables captured
+// by the literal share the state of the enclosing function.
+func (f *taintFunction) closure(lit *ast.FuncLit, args []taintLabels) []taintLabels {
+	results, returns := f.results, f.returns
+	f.results = f.resultObjects(lit.Type)
+	f.returns = make([]taintLabels, len(f.results))
+	defer func() {
+		f.results, f.returns = results, returns
+	}()
+	if f.inClosure[lit] {
+		return f.returns
+	}
+	f.inClosure[lit] = true
+	defer delete(f.inClosure, lit)
+
+	sig, _ := f.info.TypeOf(lit).(*types.Signature)
+	index := 0
+	for _, field := range lit.Type.Params.List {
+		if len(field.Names) == 0 {
+			index++
+			continue
+		}
+		for _, name := range field.Names {
+			if obj, ok := f.info.Defs[name].(*types.Var); ok {
+				switch {
+				case f.config.isSourceParam(sig, obj):
+					f.state[obj] = &taintValue{labels: taintLabels{sourceLabel: taintTrace{{node: name, what: "user input in parameter " + name.Name}}}}
+				case index < len(args) && len(args[index]) > 0:
+					f.state[obj] = &taintValue{labels: args[index].step(name, "parameter "+name.Name)}
+				default:
+					delete(f.state, obj)
 				}
 			}
+			index++
 		}
-
 	}
-	return "", "", false
+	f.stmts(lit.Body.List)
+	return f.returns
 }
 
-func findTatintedVariables(fset *token.FileSet, assignmentStatement *ast.AssignStmt, flaggedVars []inputVariables, taintedVars []string, filename string, functionName string) ([]inputVariables, []string) {
-	a1 := new(FuncVisitor)
-	a1.rhsIdents = a1.rhsIdents[:0]
-	for _, rhs := range assignmentStatement.Rhs {
-		ast.Walk(a1, rhs)
+func (f *taintFunction) stmts(list []ast.Stmt) {
+	for _, s := range list {
+		f.stmt(s)
 	}
-	flag := false
-	for _, taintVar := range taintedVars {
-		for _, rhsVar := range a1.rhsIdents {
-			if taintVar == rhsVar {
-				flag = true
+}
+
+func (f *taintFunction) stmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		f.assign(s, s.Lhs, s.Rhs, s.Tok)
+	case *ast.DeclStmt:
+		if decl, ok := s.Decl.(*ast.GenDecl); ok {
+			for _, spec := range decl.Specs {
+				if vspec, ok := spec.(*ast.ValueSpec); ok && len(vspec.Values) > 0 {
+					lhs := make([]ast.Expr, len(vspec.Names))
+					for i, name := range vspec.Names {
+						lhs[i] = name
+					}
+					f.assign(vspec, lhs, vspec.Values, token.DEFINE)
+				}
 			}
 		}
+	case *ast.ExprStmt:
+		f.expr(s.X)
+	case *ast.SendStmt:
+		f.expr(s.Chan)
+		f.expr(s.Value)
+	case *ast.GoStmt:
+		f.expr(s.Call)
+	case *ast.DeferStmt:
+		f.expr(s.Call)
+	case *ast.ReturnStmt:
+		f.ret(s)
+	case *ast.LabeledStmt:
+		f.stmt(s.Stmt)
+	case *ast.BlockStmt:
+		f.stmts(s.List)
+	case *ast.IfStmt:
+		if s.Init != nil {
+			f.stmt(s.Init)
+		}
+		f.expr(s.Cond)
+		f.stmt(s.Body)
+		if s.Else != nil {
+			f.stmt(s.Else)
+		}
+	case *ast.ForStmt:
+		if s.Init != nil {
+			f.stmt(s.Init)
+		}
+		// the body is evaluated twice to follow values tainted in a
+		// previous iteration
+		for i := 0; i < 2; i++ {
+			if s.Cond != nil {
+				f.expr(s.Cond)
+			}
+			f.stmt(s.Body)
+			if s.Post != nil {
+				f.stmt(s.Post)
+			}
+		}
+	case *ast.RangeStmt:
+		f.rangeStmt(s)
+	case *ast.SwitchStmt:
+		if s.Init != nil {
+			f.stmt(s.Init)
+		}
+		if s.Tag != nil {
+			f.expr(s.Tag)
+		}
+		f.stmt(s.Body)
+	case *ast.TypeSwitchStmt:
+		f.typeSwitch(s)
+	case *ast.SelectStmt:
+		f.stmt(s.Body)
+	case *ast.CaseClause:
+		for _, e := range s.List {
+			f.expr(e)
+		}
+		f.stmts(s.Body)
+	case *ast.CommClause:
+		if s.Comm != nil {
+			f.stmt(s.Comm)
+		}
+		f.stmts(s.Body)
 	}
-	if flag == true {
-		for _, lhs := range assignmentStatement.Lhs {
-			lhsVar := lhs.(*ast.Ident)
+}
 
-			var tempWarningInputVar inputVariables //Declare temperary variable of type inputVariables
+// rangeStmt assigns the elements of the ranged value to the loop variables.
+// The keys of a map hold data as well, the indexes of a slice do not.
+func (f *taintFunction) rangeStmt(s *ast.RangeStmt) {
+	var key, value *taintValue
+	elem := f.element(s.X)
+	var t types.Type = types.Typ[types.Invalid]
+	if x := f.info.TypeOf(s.X); x != nil {
+		t = x
+	}
+	switch t.Underlying().(type) {
+	case *types.Map:
+		key, value = &taintValue{labels: f.expr(s.X)}, elem
+	case *types.Chan:
+		key = elem
+	default:
+		value = elem
+	}
+	for i := 0; i < 2; i++ {
+		if s.Key != nil {
+			f.store(s.Key, key.step(s, "assigned to "+types.ExprString(s.Key)), nil)
+		}
+		if s.Value != nil {
+			f.store(s.Value, value.step(s, "assigned to "+types.ExprString(s.Value)), nil)
+		}
+		f.stmt(s.Body)
+	}
+}
 
-			tempWarningInputVar.filename = filename
-			tempWarningInputVar.functionName = functionName
-			tempWarningInputVar.variableName = lhsVar.Name
-			tempWarningInputVar.variableState.tainted = true
-			tempWarningInputVar.node = assignmentStatement
-			//tempWarningInputVar.columnNumber = fset.Position(lhsVar.Pos()).Column
+// element returns the value of the elements of a collection
+func (f *taintFunction) element(e ast.Expr) *taintValue {
+	if place, ok := f.place(e); ok {
+		return f.read(place.field(elementPath))
+	}
+	labels := f.expr(e)
+	if len(labels) == 0 {
+		return nil
+	}
+	return &taintValue{labels: labels}
+}
 
-			flaggedVars = append(flaggedVars, tempWarningInputVar)              //Append the LHS to warning input vars array
-			taintedVars = append(taintedVars, tempWarningInputVar.variableName) //Appending variable name into tainted list
+// typeSwitch assigns the value of the switch to the variable implicitly
+// declared by each clause
+func (f *taintFunction) typeSwitch(s *ast.TypeSwitchStmt) {
+	if s.Init != nil {
+		f.stmt(s.Init)
+	}
+	var value *taintValue
+	switch assign := s.Assign.(type) {
+	case *ast.AssignStmt:
+		if len(assign.Rhs) == 1 {
+			value = f.value(assign.Rhs[0])
+		}
+	case *ast.ExprStmt:
+		f.expr(assign.X)
+	}
+	for _, clause := range s.Body.List {
+		cc, ok := clause.(*ast.CaseClause)
+		if !ok {
+			continue
+		}
+		if obj := f.info.Implicits[cc]; obj != nil {
+			f.write(taintPlace{obj: obj}, value.step(s.Assign, "assigned to "+obj.Name()), true)
+		}
+		f.stmts(cc.Body)
+	}
+}
 
+// assign propagates the labels of the right hand side of an assignment
+func (f *taintFunction) assign(node ast.Node, lhs []ast.Expr, rhs []ast.Expr, tok token.Token) {
+	var values []*taintValue
+	if len(lhs) > 1 && len(rhs) == 1 {
+		if call, ok := unparen(rhs[0]).(*ast.CallExpr); ok {
+			for _, labels := range f.call(call) {
+				values = append(values, &taintValue{labels: labels})
+			}
+			f.construct(lhs, call)
+		} else {
+			// comma-ok expressions only taint the value
+			values = []*taintValue{f.value(rhs[0])}
+		}
+	} else {
+		for _, e := range rhs {
+			values = append(values, f.value(e))
+			if call, ok := unparen(e).(*ast.CallExpr); ok && len(rhs) == 1 {
+				f.construct(lhs, call)
+			}
 		}
 	}
-	for _, rhsVar := range a1.rhsIdents { //Does not mutate the array
-		for index := range flaggedVars {
-			if rhsVar == flaggedVars[index].variableName && flaggedVars[index].variableState.tainted == true {
-				tempWIV := &flaggedVars[index]
-				tempWIV.variableState.used = true
+	for i, e := range lhs {
+		var value *taintValue
+		if i < len(values) {
+			value = values[i]
+		}
+		var source ast.Expr
+		if len(lhs) == len(rhs) && (tok == token.ASSIGN || tok == token.DEFINE) {
+			source = rhs[i]
+		}
+		if tok != token.ASSIGN && tok != token.DEFINE {
+			value = value.merge(f.value(e))
+		}
+		f.store(e, value.step(node, "assigned to "+types.ExprString(e)), source)
+	}
+}
+
+// store updates the value of the variable, field or element assigned by
+// an expression. Assignments to an element only add to the labels of the
+// collection. A variable assigned a reference to another variable, or a
+// function literal, is recorded as such from the source expression.
+func (f *taintFunction) store(e ast.Expr, value *taintValue, source ast.Expr) {
+	if id, ok := unparen(e).(*ast.Ident); ok {
+		obj := f.object(id)
+		if _, ok := obj.(*types.Var); !ok {
+			return
+		}
+		delete(f.aliases, obj)
+		delete(f.closures, obj)
+		if source != nil {
+			if lit, ok := unparen(source).(*ast.FuncLit); ok {
+				f.closures[obj] = lit
+			}
+			if target, ok := f.place(source); ok && f.reference(source) && target.obj != obj {
+				f.aliases[obj] = target
+				delete(f.state, obj)
+				return
 			}
 		}
+		f.write(taintPlace{obj: obj}, value, true)
+		return
+	}
+	if place, ok := f.place(e); ok {
+		f.write(place, value, !place.weak())
 	}
-	return flaggedVars, taintedVars
 }
 
-func checkValidateFunctions(statement ast.Stmt, validationPackages map[string]*pkgFunctions) []string {
-	n := new(CallExpressions)
-	n.funcCalls = n.funcCalls[:0]
-	ast.Walk(n, statement)
-	validatedVars := make([]string, 0)
-	for _, callExpr := range n.funcCalls {
-		if selExpr, ok := callExpr.Fun.(*ast.SelectorExpr); ok {
-			if pkg, ok := selExpr.X.(*ast.Ident); ok {
-				if val, ok := validationPackages[pkg.Name]; ok {
-					for _, f := range val.functionList {
+func (f *taintFunction) object(id *ast.Ident) types.Object {
+	if obj := f.info.Defs[id]; obj != nil {
+		return obj
+	}
+	return f.info.Uses[id]
+}
 
-						if selExpr.Sel.Name == f {
+// place returns the location of the variable, field or element an
+// expression refers to. A pointer and the value it points to share the
+// same location, and variables holding a reference to another variable
+// are resolved to it.
+func (f *taintFunction) place(e ast.Expr) (taintPlace, bool) {
+	switch x := e.(type) {
+	case *ast.Ident:
+		obj, ok := f.object(x).(*types.Var)
+		if !ok {
+			return taintPlace{}, false
+		}
+		if target, ok := f.aliases[obj]; ok {
+			return target, true
+		}
+		return taintPlace{obj: obj}, true
+	case *ast.ParenExpr:
+		return f.place(x.X)
+	case *ast.SelectorExpr:
+		if selection, ok := f.info.Selections[x]; ok && selection.Kind() == types.FieldVal {
+			if place, ok := f.place(x.X); ok {
+				return place.field(x.Sel.Name), true
+			}
+		}
+	case *ast.IndexExpr:
+		t := f.info.TypeOf(x.X)
+		if t == nil {
+			break
+		}
+		if ptr, ok := t.Underlying().(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		switch t.Underlying().(type) {
+		case *types.Slice, *types.Array, *types.Map:
+			if place, ok := f.place(x.X); ok {
+				return place.field(elementPath), true
+			}
+		}
+	case *ast.StarExpr:
+		return f.place(x.X)
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			return f.place(x.X)
+		}
+	}
+	return taintPlace{}, false
+}
 
-							for _, argument := range callExpr.Args {
-								if arg, ok := argument.(*ast.Ident); ok {
-									validatedVars = append(validatedVars, arg.Name)
-								}
-							}
-						}
-					}
+func (f *taintFunction) ret(s *ast.ReturnStmt) {
+	var values []taintLabels
+	switch {
+	case len(s.Results) == 0:
+		for _, obj := range f.results {
+			if obj != nil {
+				values = append(values, f.read(taintPlace{obj: obj}).flatten())
+			} else {
+				values = append(values, nil)
+			}
+		}
+	case len(s.Results) == 1 && len(f.results) > 1:
+		if call, ok := unparen(s.Results[0]).(*ast.CallExpr); ok {
+			values = f.call(call)
+		}
+	default:
+		for _, e := range s.Results {
+			values = append(values, f.expr(e))
+		}
+	}
+	for i := range f.returns {
+		if i < len(values) {
+			f.returns[i] = f.returns[i].union(values[i].step(s, "returned"))
+		}
+	}
+}
+
+// value evaluates an expression, keeping the labels of its fields and
+// elements apart
+func (f *taintFunction) value(e ast.Expr) *taintValue {
+	if place, ok := f.place(e); ok {
+		return f.read(place)
+	}
+	switch x := unparen(e).(type) {
+	case *ast.CompositeLit:
+		return f.composite(x)
+	case *ast.UnaryExpr:
+		if lit, ok := unparen(x.X).(*ast.CompositeLit); ok && x.Op == token.AND {
+			return f.composite(lit)
+		}
+	}
+	labels := f.expr(e)
+	if len(labels) == 0 {
+		return nil
+	}
+	return &taintValue{labels: labels}
+}
+
+// composite evaluates a composite literal. The fields of a struct are
+// tainted separately, the elements of a collection together.
+func (f *taintFunction) composite(lit *ast.CompositeLit) *taintValue {
+	value := &taintValue{}
+	set := func(name string, field *taintValue) {
+		if field.empty() {
+			return
+		}
+		if value.fields == nil {
+			value.fields = make(map[string]*taintValue)
+		}
+		value.fields[name] = value.fields[name].merge(field)
+	}
+	t := f.info.TypeOf(lit)
+	if t == nil {
+		return &taintValue{labels: f.expr(lit)}
+	}
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if st, ok := t.Underlying().(*types.Struct); ok {
+		for i, elt := range lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if key, ok := kv.Key.(*ast.Ident); ok {
+					set(key.Name, f.value(kv.Value))
 				}
+			} else if i < st.NumFields() {
+				set(st.Field(i).Name(), f.value(elt))
+			}
+		}
+		return value
+	}
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			set(elementPath, (&taintValue{labels: f.expr(kv.Key)}).merge(f.value(kv.Value)))
+		} else {
+			set(elementPath, f.value(elt))
+		}
+	}
+	return value
+}
 
+// expr evaluates the labels of an expression
+func (f *taintFunction) expr(e ast.Expr) taintLabels {
+	if place, ok := f.place(e); ok {
+		// the index of an element is evaluated for its calls but does
+		// not taint the element
+		if index, ok := unparen(e).(*ast.IndexExpr); ok {
+			f.expr(index.Index)
+		}
+		return f.read(place).flatten()
+	}
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return f.expr(e.X)
+	case *ast.SelectorExpr:
+		if _, ok := f.info.Selections[e]; ok {
+			return f.expr(e.X)
+		}
+		if v, ok := f.info.Uses[e.Sel].(*types.Var); ok && f.config.isSourceVar(v) {
+			return taintLabels{sourceLabel: taintTrace{{node: e, what: "user input in " + types.ExprString(e)}}}
+		}
+	case *ast.StarExpr:
+		return f.expr(e.X)
+	case *ast.UnaryExpr:
+		return f.expr(e.X)
+	case *ast.BinaryExpr:
+		return f.expr(e.X).union(f.expr(e.Y))
+	case *ast.IndexExpr:
+		return f.expr(e.X).union(f.expr(e.Index))
+	case *ast.SliceExpr:
+		labels := f.expr(e.X)
+		for _, index := range []ast.Expr{e.Low, e.High, e.Max} {
+			if index != nil {
+				labels = labels.union(f.expr(index))
 			}
 		}
+		return labels
+	case *ast.TypeAssertExpr:
+		return f.expr(e.X)
+	case *ast.KeyValueExpr:
+		return f.expr(e.Key).union(f.expr(e.Value))
+	case *ast.CompositeLit:
+		return f.composite(e).flatten()
+	case *ast.FuncLit:
+		f.closure(e, nil)
+	case *ast.CallExpr:
+		var labels taintLabels
+		for _, result := range f.call(e) {
+			labels = labels.union(result)
+		}
+		return labels
+	}
+	return nil
+}
 
+// funcLit returns the function literal called by a call expression
+func (f *taintFunction) funcLit(call *ast.CallExpr) *ast.FuncLit {
+	switch fun := unparen(call.Fun).(type) {
+	case *ast.FuncLit:
+		return fun
+	case *ast.Ident:
+		return f.closures[f.object(fun)]
 	}
-	return validatedVars
+	return nil
 }
 
-func TaintAnalysis(gosec *Analyzer) {
+// call evaluates the labels of the results of a call. The results of the
+// functions of the scanned packages are derived from their summary, the
+// results of function literals from their body, and the results of other
+// functions from all of their arguments.
+func (f *taintFunction) call(call *ast.CallExpr) []taintLabels {
+	if tv, ok := f.info.Types[call.Fun]; ok && tv.IsType() {
+		if len(call.Args) == 1 {
+			return []taintLabels{f.expr(call.Args[0])}
+		}
+		return nil
+	}
 
-	/////////////////////// Configuration Variables///////////////////
+	// the receiver of a method is passed as its first argument
+	args := call.Args
+	if sel, ok := unparen(call.Fun).(*ast.SelectorExpr); ok {
+		if selection, ok := f.info.Selections[sel]; ok && selection.Kind() == types.MethodVal {
+			args = append([]ast.Expr{sel.X}, call.Args...)
+		}
+	}
+	receiver := len(args) - len(call.Args)
+	labels := make([]taintLabels, len(args))
+	for i, arg := range args {
+		labels[i] = f.expr(arg)
+		if trace, ok := labels[i][sourceLabel]; ok && f.flow != nil && i >= receiver {
+			f.flow(arg, trace)
+		}
+	}
 
-	packages := make(map[string]*packageDetails)
-	// Okay wait a min 
-	packages["\"github.com/go-ozzo/ozzo-validation\""] = &packageDetails{packageName: "validation", constructor: "", functionList: []string{"Validate"}}
-	packages["\"gopkg.in/go-playground/validator.v9\""] = &packageDetails{packageName: "validator", constructor: "New", functionList: []string{"Struct", "Var"}}
+	results := 1
+	if tuple, ok := f.info.TypeOf(call).(*types.Tuple); ok {
+		results = tuple.Len()
+	}
+	values := make([]taintLabels, results)
 
-	validationPackages := make(map[string]*pkgFunctions)
+	if lit := f.funcLit(call); lit != nil {
+		passed := "passed to " + types.ExprString(call.Fun)
+		for i := range labels {
+			labels[i] = labels[i].step(call, passed)
+		}
+		copy(values, f.closure(lit, labels))
+		return values
+	}
+	if f.sanitize(call) {
+		return values
+	}
+	callee := calleeFunc(f.info, call)
+	if source := f.source(callee, call); source != nil {
+		input := taintLabels{sourceLabel: taintTrace{{node: call, what: "user input from " + types.ExprString(call.Fun)}}}
+		if len(source.Args) == 0 {
+			for j := range values {
+				values[j] = input
+			}
+			return values
+		}
+		for i, arg := range call.Args {
+			if containsIndex(source.Args, i) {
+				if place, ok := f.place(arg); ok {
+					f.write(place, &taintValue{labels: input}, false)
+				}
+			}
+		}
+		return values
+	}
+	if sink := f.sink(callee, call); sink != nil {
+		for i := range call.Args {
+			if len(sink.Args) == 0 || containsIndex(sink.Args, i) {
+				f.reach(call, sink, labels[receiver+i].step(call, "used by "+types.ExprString(call.Fun)))
+			}
+		}
+	}
 
-	////////////////////////////////////////////////////////////////
+	if summary, ok := f.summaries[callee]; ok {
+		sig := callee.Type().(*types.Signature)
+		params := sig.Params().Len()
+		if sig.Recv() != nil {
+			params++
+		}
+		// variadic arguments are merged into the last parameter
+		paramLabels := make([]taintLabels, params)
+		for i, argLabels := range labels {
+			param := i
+			if param >= params {
+				param = params - 1
+			}
+			if param >= 0 {
+				paramLabels[param] = paramLabels[param].union(argLabels)
+			}
+		}
+		passed := "passed to " + types.ExprString(call.Fun)
+		for _, sink := range f.config.sinks {
+			sinkParams := summary.sinks[sink]
+			for _, param := range sinkParams.sorted() {
+				if param >= 0 && param < params {
+					f.reach(call, sink, f.through(paramLabels[param].step(call, passed), sinkParams[param]))
+				}
+			}
+		}
+		for j := range values {
+			if j >= len(summary.returns) {
+				break
+			}
+			returns := summary.returns[j]
+			for _, label := range returns.sorted() {
+				if label == sourceLabel {
+					values[j] = values[j].union(taintLabels{sourceLabel: returns[label]})
+				} else if label < params {
+					values[j] = values[j].union(f.through(paramLabels[label].step(call, passed), returns[label]))
+				}
+			}
+		}
+		return values
+	}
 
-	filename := gosec.context.FileSet.File(gosec.context.Root.Package).Name()
-	filename = path.Base(filename)
+	var all taintLabels
+	for _, argLabels := range labels {
+		all = all.union(argLabels)
+	}
+	if len(all) > 0 {
+		// the arguments passed by reference may be filled with the data,
+		// e.g. json.NewDecoder(r.Body).Decode(&input)
+		for _, arg := range call.Args {
+			if f.reference(arg) {
+				if place, ok := f.place(arg); ok {
+					f.write(place, &taintValue{labels: all.step(call, "written to "+types.ExprString(arg))}, false)
+				}
+			}
+		}
+	}
+	for j := range values {
+		values[j] = all
+	}
+	return values
+}
 
-	fset := gosec.context.FileSet
-	node := gosec.context.Root
+// through extends the traces of the arguments of a call with the trace of
+// the flow inside of the called function
+func (f *taintFunction) through(labels taintLabels, trace taintTrace) taintLabels {
+	if len(labels) == 0 {
+		return labels
+	}
+	through := make(taintLabels, len(labels))
+	for label, argTrace := range labels {
+		through[label] = argTrace.concat(trace)
+	}
+	return through
+}
 
-	for _, i := range node.Imports {
-		if val, ok := packages[i.Path.Value]; ok {
-			if i.Name != nil {
-				validationPackages[i.Name.Name] = &pkgFunctions{constructor: val.constructor, functionList: val.functionList}
-			} else {
-				validationPackages[val.packageName] = &pkgFunctions{constructor: val.constructor, functionList: val.functionList}
-			}
-		}
-	}
-
-	//Variables that have been flagged unsafe
-	flaggedVars := make([]inputVariables, 0)
-
-	for _, dec := range node.Decls {
-		if fnDecl, ok := dec.(*ast.FuncDecl); ok {
-			if v, ok := checkFuncDecl(fnDecl); ok {
-				//Tainted variables found within a function
-				taintedVars := []string{v}
-
-				b := *fnDecl.Body
-				for _, statement := range b.List {
-					validatedVars := checkValidateFunctions(statement, validationPackages)
-
-					for _, variable := range validatedVars {
-						for index := range flaggedVars {
-							if flaggedVars[index].variableName == variable {
-								temp := &flaggedVars[index]
-								temp.variableState.validated = true
-								temp.variableState.tainted = false
-							}
-						}
-						for index := range taintedVars {
-							if variable == taintedVars[index] {
-								taintedVars[len(taintedVars)-1], taintedVars[index] = taintedVars[index], taintedVars[len(taintedVars)-1]
-								taintedVars = taintedVars[:len(taintedVars)-1]
-								break
-							}
-						}
-					}
+// reference checks whether an argument gives access to the memory of a
+// variable of the caller
+func (f *taintFunction) reference(arg ast.Expr) bool {
+	if unary, ok := unparen(arg).(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		return true
+	}
+	switch f.info.TypeOf(arg).(type) {
+	case *types.Pointer, *types.Slice, *types.Map:
+		return true
+	}
+	return false
+}
 
-					if assignStmt, ok := statement.(*ast.AssignStmt); ok {
-						if pkg, obj, err := checkValidateConstructor(assignStmt, validationPackages); err {
-							validationPackages[obj] = &pkgFunctions{constructor: validationPackages[pkg].constructor, functionList: validationPackages[pkg].functionList}
-						}
-						flaggedVars, taintedVars = findTatintedVariables(fset, assignStmt, flaggedVars, taintedVars, filename, fnDecl.Name.Name)
-					}
-				}
+// reach records that values with the given labels are used by a sink
+func (f *taintFunction) reach(node ast.Node, sink *taintSink, labels taintLabels) {
+	for _, label := range labels.sorted() {
+		if label != sourceLabel {
+			if _, ok := f.sinks[sink][label]; !ok {
+				f.sinks[sink] = f.sinks[sink].union(taintLabels{label: labels[label]})
+			}
+			continue
+		}
+		if f.report != nil && !f.reported[node][sink] {
+			if f.reported[node] == nil {
+				f.reported[node] = make(map[*taintSink]bool)
+			}
+			f.reported[node][sink] = true
+			f.report(node, sink, labels[label])
+		}
+	}
+}
 
-				for _, i := range flaggedVars {
-					if (i.variableState.tainted == true && i.variableState.used == true) {
-						issue := NewIssue(gosec.context, i.node, "TaintAnalysis", "Variable tainted with user input and used before validation", Medium, Low)
-						gosec.issues = append(gosec.issues, issue)
-						gosec.stats.NumFound++						
-					}
+// source returns the source of user input called by a call expression
+func (f *taintFunction) source(callee *types.Func, call *ast.CallExpr) *taintSource {
+	if callee != nil {
+		return f.config.sourceFunc(funcName(callee))
+	}
+	if pkg, name, ok := importedCallee(f.info, call); ok {
+		return f.config.sourceFunc(pkg, "", name)
+	}
+	return nil
+}
+
+// sink returns the sink called by a call expression
+func (f *taintFunction) sink(callee *types.Func, call *ast.CallExpr) *taintSink {
+	if callee != nil {
+		return f.config.sink(funcName(callee))
+	}
+	if pkg, name, ok := importedCallee(f.info, call); ok {
+		return f.config.sink(pkg, "", name)
+	}
+	return nil
+}
+
+// sanitize removes the labels of the arguments of a validation function
+func (f *taintFunction) sanitize(call *ast.CallExpr) bool {
+	if !f.isSanitizer(call) {
+		return false
+	}
+	for _, arg := range call.Args {
+		if place, ok := f.place(arg); ok {
+			f.write(place, nil, true)
+		}
+	}
+	return true
+}
+
+func (f *taintFunction) isSanitizer(call *ast.CallExpr) bool {
+	if callee := calleeFunc(f.info, call); callee != nil {
+		pkg, _, name := funcName(callee)
+		for _, sanitizer := range f.config.sanitizers {
+			if sanitizer.Package == pkg && contains(sanitizer.Functions, name) {
+				return true
+			}
+		}
+	}
+	if pkg, name, ok := importedCallee(f.info, call); ok {
+		for _, sanitizer := range f.config.sanitizers {
+			if sanitizer.Package == pkg && sanitizer.Constructor == "" && contains(sanitizer.Functions, name) {
+				return true
+			}
+		}
+	}
+	// methods of a validator created by a constructor of the package
+	if sel, ok := unparen(call.Fun).(*ast.SelectorExpr); ok {
+		if id, ok := sel.X.(*ast.Ident); ok {
+			if sanitizer, ok := f.validators[f.object(id)]; ok {
+				return contains(sanitizer.Functions, sel.Sel.Name)
+			}
+		}
+	}
+	return false
+}
+
+// construct records the validators created by a call to a constructor
+func (f *taintFunction) construct(lhs []ast.Expr, call *ast.CallExpr) {
+	pkg, name, ok := importedCallee(f.info, call)
+	if !ok || len(lhs) == 0 {
+		return
+	}
+	for _, sanitizer := range f.config.sanitizers {
+		if sanitizer.Package == pkg && sanitizer.Constructor != "" && sanitizer.Constructor == name {
+			if id, ok := lhs[0].(*ast.Ident); ok {
+				if obj := f.object(id); obj != nil {
+					f.validators[obj] = sanitizer
 				}
 			}
 		}
+	}
+}
 
+// TaintAnalysis reports the user input reaching a sink before it is
+// validated, in the functions of the file being scanned. The flows
+// through the functions of the scanned packages are followed using the
+// summaries computed when the packages were loaded. The issues hold the
+// trace of the flow from the source to the sink. The call arguments
+// holding user input are recorded in the context for the rules.
+func TaintAnalysis(gosec *Analyzer) {
+	ctx := gosec.context
+	ctx.Tainted = make(map[ast.Expr][]*TraceStep)
+	trace := func(steps taintTrace) []*TraceStep {
+		var trace []*TraceStep
+		for _, step := range steps {
+			trace = append(trace, NewTraceStep(ctx, step.node, step.what))
+		}
+		return trace
+	}
+	for _, decl := range ctx.Root.Decls {
+		if fnDecl, ok := decl.(*ast.FuncDecl); ok && fnDecl.Body != nil {
+			analyzeFunction(ctx.Info, fnDecl, gosec.taint, gosec.summaries, func(node ast.Node, sink *taintSink, steps taintTrace) {
+				desc := "User input used before validation"
+				if call, ok := steps[len(steps)-1].node.(*ast.CallExpr); ok {
+					desc = fmt.Sprintf("User input used by %s before validation", types.ExprString(call.Fun))
+				}
+				issue := NewIssue(ctx, node, sink.RuleID, desc, sink.Severity, Low)
+				issue.Trace = trace(steps)
+				gosec.tainted[node] = append(gosec.tainted[node], issue)
+			}, func(arg ast.Expr, steps taintTrace) {
+				if _, ok := ctx.Tainted[arg]; !ok {
+					ctx.Tainted[arg] = trace(steps)
+				}
+			})
+		}
 	}
 }
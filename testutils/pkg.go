@@ -57,7 +57,9 @@ func (p *TestPackage) AddFile(filename, content string) {
 	p.Files[path.Join(p.Path, filename)] = content
 }
 
-func (p *TestPackage) write() error {
+// Write persists the files of the package to disk, without building it as
+// Build does. The analyzer loads the packages it checks by itself.
+func (p *TestPackage) Write() error {
 	if p.ondisk {
 		return nil
 	}
@@ -75,7 +77,7 @@ func (p *TestPackage) Build() error {
 	if p.build != nil {
 		return nil
 	}
-	if err := p.write(); err != nil {
+	if err := p.Write(); err != nil {
 		return err
 	}
 	basePackage, err := build.Default.ImportDir(p.Path, build.ImportComment)
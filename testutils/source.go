@@ -2,13 +2,13 @@ package testutils
 
 // CodeSample encapsulates a snippet of source code that compiles, and how many errors should be detected
 type CodeSample struct {
-	Code   []strings
+	Code   []string
 	Errors int
 }
 
 var (
-	// SampleCodehardcreds code snippets for hardcoded credentials
-	SampleCodehardcreds = []CodeSample{{[]string{`
+	// SampleCodeHardcreds code snippets for hardcoded credentials
+	SampleCodeHardcreds = []CodeSample{{[]string{`
 package main
 import "fmt"
 func main() {
@@ -68,8 +68,8 @@ func main() {
 	println(ATNStateTokenStart)
 }`}, 1}}
 
-	// SampleCodebind-interfaces code snippets for network binding
-	SampleCodebind-interfaces = []CodeSample{
+	// SampleCodeBindInterfaces code snippets for network binding
+	SampleCodeBindInterfaces = []CodeSample{
 		// Bind to all networks explicitly
 		{[]string{`
 package main
@@ -151,8 +151,8 @@ func main() {
 	defer l.Close()
 }`}, 1},
 	}
-	// SampleCodeunsafe-block find instances of unsafe blocks for auditing purposes
-	SampleCodeunsafe-block = []CodeSample{
+	// SampleCodeUnsafeBlock find instances of unsafe blocks for auditing purposes
+	SampleCodeUnsafeBlock = []CodeSample{
 		{[]string{`
 package main
 import (
@@ -173,8 +173,8 @@ func main() {
    	fmt.Printf("\nintPtr=%p, *intPtr=%d.\n\n", intPtr, *intPtr)
 }`}, 3}}
 
-	// SampleCodeerror-check finds errors that aren't being handled
-	SampleCodeerror-check = []CodeSample{
+	// SampleCodeErrorCheck finds errors that aren't being handled
+	SampleCodeErrorCheck = []CodeSample{
 		{[]string{`
 package main
 import "fmt"
@@ -231,8 +231,8 @@ package main
 func dummy(){}
 `}, 0}}
 
-	// SampleCodeerror-checkAudit finds errors that aren't being handled in audit mode
-	SampleCodeerror-checkAudit = []CodeSample{
+	// SampleCodeErrorCheckAudit finds errors that aren't being handled in audit mode
+	SampleCodeErrorCheckAudit = []CodeSample{
 		{[]string{`
 package main
 import "fmt"
@@ -288,8 +288,8 @@ func main() {
 package main
 func dummy(){}
 `}, 0}}
-	// SampleCodemath-audit - bignum overflow
-	SampleCodemath-audit = []CodeSample{{[]string{`
+	// SampleCodeMathAudit - bignum overflow
+	SampleCodeMathAudit = []CodeSample{{[]string{`
 package main
 import (
 	"math/big"
@@ -305,8 +305,8 @@ func main() {
     z = z.Exp(x, y, m)
 }`}, 1}}
 
-	// SampleCodeinsecure-ssh-key - ssh InsecureIgnoreHostKey
-	SampleCodeinsecure-ssh-key = []CodeSample{{[]string{`
+	// SampleCodeInsecureSshKey - ssh InsecureIgnoreHostKey
+	SampleCodeInsecureSshKey = []CodeSample{{[]string{`
 package main
 import (
         "golang.org/x/crypto/ssh"
@@ -315,8 +315,8 @@ func main() {
         _ =  ssh.InsecureIgnoreHostKey()
 }`}, 1}}
 
-	// SampleCodetaint-http - SSRF via http requests with variable url
-	SampleCodetaint-http = []CodeSample{{[]string{`
+	// SampleCodeTaintHttp - SSRF via http requests with variable url
+	SampleCodeTaintHttp = []CodeSample{{[]string{`
 package main
 import (
 	"net/http"
@@ -355,9 +355,115 @@ func main() {
 		fmt.Println(err)
     	}
       	fmt.Println(resp.Status)
+}`}, 0}, {[]string{`
+package main
+
+import (
+	"io"
+	"net/http"
+)
+
+const base = "http://127.0.0.1/"
+
+func proxy(w http.ResponseWriter, r *http.Request) {
+	resp, err := http.Get(base + r.URL.Query().Get("u"))
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(w, resp.Body)
+}
+
+func main() {
+	http.HandleFunc("/proxy", proxy)
+}`}, 1}}
+	// SampleCodeSlowloris - HTTP servers without read timeouts
+	SampleCodeSlowloris = []CodeSample{{[]string{`
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func main() {
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello")
+	})
+	http.ListenAndServe(":8080", nil)
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+func main() {
+	server := &http.Server{
+		Addr:         ":8080",
+		WriteTimeout: 10 * time.Second,
+	}
+	server.ListenAndServe()
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"net/http"
+)
+
+func main() {
+	server := &http.Server{
+		Addr:              ":8080",
+		ReadHeaderTimeout: 0,
+	}
+	server.ListenAndServe()
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+func main() {
+	server := &http.Server{
+		Addr:              ":8080",
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	server.ReadHeaderTimeout = 0
+	server.ListenAndServe()
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+func main() {
+	server := &http.Server{
+		Addr:              ":8080",
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	server.ListenAndServe()
+}`}, 0}, {[]string{`
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+func main() {
+	var server http.Server
+	server = http.Server{Addr: ":8080"}
+	server.ReadTimeout = 30 * time.Second
+	server.ListenAndServe()
 }`}, 0}}
-	// SampleCodesql-format-string - SQL injection via format string
-	SampleCodesql-format-string = []CodeSample{
+
+	// SampleCodeSqlFormatString - SQL injection via format string
+	SampleCodeSqlFormatString = []CodeSample{
 		{[]string{`
 // Format string without proper quoting
 package main
@@ -446,8 +552,8 @@ func main(){
 	fmt.Sprintln()
 }`}, 0}}
 
-	// SampleCodesql-string-concat - SQL query string building via string concatenation
-	SampleCodesql-string-concat = []CodeSample{
+	// SampleCodeSqlStringConcat - SQL query string building via string concatenation
+	SampleCodeSqlStringConcat = []CodeSample{
 		{[]string{`
 package main
 import (
@@ -522,8 +628,8 @@ func main(){
 }
 `}, 0}}
 
-	// SampleCodeunescaped-html-data - Template checks
-	SampleCodeunescaped-html-data = []CodeSample{
+	// SampleCodeUnescapedHtmlData - Template checks
+	SampleCodeUnescapedHtmlData = []CodeSample{
 		{[]string{`
 // We assume that hardcoded template strings are safe as the programmer would
 // need to be explicitly shooting themselves in the foot (as below)
@@ -592,8 +698,8 @@ func main() {
 	t.Execute(os.Stdout, v)
 }`}, 1}}
 
-	// SampleCodecmd-exec - Subprocess auditing
-	SampleCodecmd-exec = []CodeSample{{[]string{`
+	// SampleCodeCmdExec - Subprocess auditing
+	SampleCodeCmdExec = []CodeSample{{[]string{`
 package main
 import "syscall"
 func main() {
@@ -643,10 +749,23 @@ func main() {
 	log.Printf("Waiting for command to finish...")
 	err = cmd.Wait()
 	log.Printf("Command finished with error: %v", err)
+}`}, 1}, {[]string{`
+package main
+import (
+	"net/http"
+	"os/exec"
+)
+func run(shell string, r *http.Request) error {
+	return exec.Command(shell, "-c", r.FormValue("cmd")).Run()
+}
+func main() {
+	http.HandleFunc("/run", func(w http.ResponseWriter, r *http.Request) {
+		run("/bin/sh", r)
+	})
 }`}, 1}}
 
-	// SampleCodedir-perm - mkdir permission check
-	SampleCodedir-perm = []CodeSample{{[]string{`
+	// SampleCodeDirPerm - mkdir permission check
+	SampleCodeDirPerm = []CodeSample{{[]string{`
 package main
 import "os"
 func main() {
@@ -655,8 +774,8 @@ func main() {
 	os.MkdirAll("/tmp/mydir/mysubidr", 0775)
 }`}, 2}}
 
-	// SampleCodepoor-chmod - file create / chmod permissions check
-	SampleCodepoor-chmod = []CodeSample{{[]string{`
+	// SampleCodePoorChmod - file create / chmod permissions check
+	SampleCodePoorChmod = []CodeSample{{[]string{`
 package main
 import "os"
 func main() {
@@ -666,8 +785,8 @@ func main() {
 	os.OpenFile("/tmp/thing", os.O_CREATE|os.O_WRONLY, 0600)
 }`}, 2}}
 
-	// SampleCodepredict-path - bad tempfile permissions & hardcoded shared path
-	SampleCodepredict-path = []CodeSample{{[]string{`
+	// SampleCodePredictPath - bad tempfile permissions & hardcoded shared path
+	SampleCodePredictPath = []CodeSample{{[]string{`
 package samples
 import (
 	"io/ioutil"
@@ -679,8 +798,8 @@ func main() {
 	ioutil.WriteFile("/tmp/demo2", []byte("This is some data"), 0644)
 }`}, 2}}
 
-	// SampleCodetaint-file-path - potential file inclusion vulnerability
-	SampleCodetaint-file-path = []CodeSample{{[]string{`
+	// SampleCodeTaintFilePath - potential file inclusion vulnerability
+	SampleCodeTaintFilePath = []CodeSample{{[]string{`
 package main
 import (
 "os"
@@ -780,8 +899,8 @@ func main() {
 	log.Print(body)
 }`}, 1}}
 
-	// SampleCodefile-traverse - File path traversal when extracting zip archives
-	SampleCodefile-traverse = []CodeSample{{[]string{`
+	// SampleCodeFileTraverse - File path traversal when extracting zip or tar archives
+	SampleCodeFileTraverse = []CodeSample{{[]string{`
 package unzip
 
 import (
@@ -872,128 +991,708 @@ func unzip(archive, target string) error {
 	}
 
 	return nil
-}`}, 1}}
+}`}, 1}, {[]string{`
+package untar
 
-	// SampleCodeinsecure-lib - Use of weak crypto MD5
-	SampleCodeinsecure-lib = []CodeSample{
-		{[]string{`
-package main
 import (
-	"crypto/md5"
-	"fmt"
+	"archive/tar"
 	"io"
-	"log"
 	"os"
+	"path/filepath"
 )
-func main() {
-	f, err := os.Open("file.txt")
-	if err != nil {
-		log.Fatal(err)
+
+func untar(r io.Reader, target string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(target, header.Name)
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		f.Close()
 	}
-	defer f.Close()
+}`}, 1}, {[]string{`
+package unzip
 
-	h := md5.New()
-	if _, err := io.Copy(h, f); err != nil {
-		log.Fatal(err)
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+)
+
+func mkdirs(reader *zip.Reader, target string) error {
+	for _, file := range reader.File {
+		header := file.FileHeader
+		if err := os.MkdirAll(filepath.Join(target, header.Name), 0750); err != nil {
+			return err
+		}
 	}
-	fmt.Printf("%x", h.Sum(nil))
-}`}, 1}}
+	return nil
+}`}, 1}, {[]string{`
+package untar
 
-	// SampleCodeinsecure-libb - Use of weak crypto SHA1
-	SampleCodeinsecure-libb = []CodeSample{
-		{[]string{`
-package main
 import (
-	"crypto/sha1"
+	"archive/tar"
+	"os"
+)
+
+func link(header *tar.Header, path string) error {
+	return os.Symlink(header.Linkname, path)
+}`}, 1}, {[]string{`
+package unzip
+
+import (
+	"archive/zip"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"path/filepath"
+	"strings"
 )
-func main() {
-	f, err := os.Open("file.txt")
+
+func unzip(archive, target string) error {
+	reader, err := zip.OpenReader(archive)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer f.Close()
+	defer reader.Close()
 
-	h := sha1.New()
-	if _, err := io.Copy(h, f); err != nil {
-		log.Fatal(err)
+	for _, file := range reader.File {
+		path := filepath.Join(target, file.Name)
+		if !strings.HasPrefix(filepath.Clean(path), filepath.Clean(target)+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal file path: %s", path)
+		}
+
+		fileReader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		targetFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			fileReader.Close()
+			return err
+		}
+		_, err = io.Copy(targetFile, io.LimitReader(fileReader, 1<<20))
+		targetFile.Close()
+		fileReader.Close()
+		if err != nil {
+			return err
+		}
 	}
-	fmt.Printf("%x", h.Sum(nil))
-}`}, 1}}
+	return nil
+}`}, 0}, {[]string{`
+package untar
 
-	// SampleCodebad-tls - TLS settings
-	SampleCodebad-tls = []CodeSample{{[]string{`
-// InsecureSkipVerify
-package main
 import (
-	"crypto/tls"
+	"archive/tar"
 	"fmt"
-	"net/http"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 )
-func main() {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
 
-	client := &http.Client{Transport: tr}
-	_, err := client.Get("https://golang.org/")
-	if err != nil {
-		fmt.Println(err)
+func untar(r io.Reader, target string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(target, header.Name)
+		rel, err := filepath.Rel(target, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("illegal file path: %s", header.Name)
+		}
+		if header.Typeflag == tar.TypeSymlink {
+			link := filepath.Join(filepath.Dir(path), header.Linkname)
+			if !strings.HasPrefix(link, filepath.Clean(target)+string(os.PathSeparator)) {
+				return fmt.Errorf("illegal link: %s", header.Linkname)
+			}
+			if err := os.Symlink(header.Linkname, path); err != nil {
+				return err
+			}
+		}
 	}
-}`}, 1}, {[]string{
-		`
-// Insecure minimum version
-package main
+}`}, 0}, {[]string{`
+package unzip
+
 import (
-	"crypto/tls"
+	"archive/zip"
 	"fmt"
-	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 )
-func main() {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{MinVersion: 0},
-	}
-	client := &http.Client{Transport: tr}
-	_, err := client.Get("https://golang.org/")
-	if err != nil {
-		fmt.Println(err)
+
+func unzip(reader *zip.Reader, target string) error {
+	for _, file := range reader.File {
+		path := filepath.Join(target, file.Name)
+		if !strings.HasPrefix(filepath.Clean(path), os.TempDir()) {
+			return fmt.Errorf("illegal file path: %s", path)
+		}
+		if err := os.MkdirAll(path, 0750); err != nil {
+			return err
+		}
 	}
+	return nil
 }`}, 1}, {[]string{`
-// Insecure max version
-package main
+package unzip
+
 import (
-	"crypto/tls"
+	"archive/zip"
 	"fmt"
-	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 )
-func main() {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{MaxVersion: 0},
-	}
-	client := &http.Client{Transport: tr}
-	_, err := client.Get("https://golang.org/")
-	if err != nil {
-		fmt.Println(err)
+
+func unzip(reader *zip.Reader, target string) error {
+	for _, file := range reader.File {
+		path := filepath.Join(target, file.Name)
+		if !strings.HasPrefix(filepath.Clean(path), filepath.Clean(target)) {
+			return fmt.Errorf("illegal file path: %s", path)
+		}
+		if err := os.MkdirAll(path, 0750); err != nil {
+			return err
+		}
 	}
-}
-`}, 1}, {
-		[]string{`
-// Insecure ciphersuite selection
-package main
+	return nil
+}`}, 1}, {[]string{`
+package unzip
+
 import (
-	"crypto/tls"
+	"archive/zip"
 	"fmt"
-	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 )
-func main() {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{CipherSuites: []uint16{
-						tls.TLS_RSA_WITH_RC4_128_SHA,
-						tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
-						},},
+
+func unzip(reader *zip.Reader, target string) error {
+	for _, file := range reader.File {
+		path := filepath.Join(target, file.Name)
+		if err := os.MkdirAll(path, 0750); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(filepath.Clean(path), filepath.Clean(target)+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal file path: %s", path)
+		}
+	}
+	return nil
+}`}, 1}, {[]string{`
+package untar
+
+import (
+	"archive/tar"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func untar(r io.Reader, target string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(target, header.Name)
+		rel, err := filepath.Rel(target, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			log.Printf("illegal file path: %s", header.Name)
+		}
+		if err := os.MkdirAll(path, 0750); err != nil {
+			return err
+		}
+	}
+}`}, 1}}
+
+	// SampleCodeDecompressionBomb - Reading compressed data without a limit
+	SampleCodeDecompressionBomb = []CodeSample{{[]string{`
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+func main() {
+	f, err := os.Open("data.gz")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		panic(err)
+	}
+	io.Copy(os.Stdout, r)
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+)
+
+func main() {
+	reader, err := zip.OpenReader("data.zip")
+	if err != nil {
+		panic(err)
+	}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			panic(err)
+		}
+		io.Copy(os.Stdout, rc)
+		rc.Close()
+	}
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+)
+
+func main() {
+	tr := tar.NewReader(os.Stdin)
+	for {
+		if _, err := tr.Next(); err != nil {
+			break
+		}
+		io.Copy(os.Stdout, tr)
+	}
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"bufio"
+	"compress/zlib"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	zr, err := zlib.NewReader(os.Stdin)
+	if err != nil {
+		panic(err)
+	}
+	br := bufio.NewReader(zr)
+	data, err := ioutil.ReadAll(br)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(len(data))
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"compress/flate"
+	"io"
+	"os"
+)
+
+func main() {
+	fr := flate.NewReader(os.Stdin)
+	defer fr.Close()
+	io.Copy(os.Stdout, io.LimitReader(fr, 1024*1024))
+}`}, 0}, {[]string{`
+package main
+
+import (
+	"compress/bzip2"
+	"io"
+	"os"
+)
+
+func main() {
+	br := bzip2.NewReader(os.Stdin)
+	io.CopyN(os.Stdout, br, 1024*1024)
+}`}, 0}, {[]string{`
+package main
+
+import (
+	"compress/bzip2"
+	"io"
+	"os"
+)
+
+const maxSize = 1024 * 1024
+
+func main() {
+	var r io.Reader = bzip2.NewReader(os.Stdin)
+	r = io.LimitReader(r, maxSize)
+	io.Copy(os.Stdout, r)
+}`}, 0}}
+
+	// SampleCodeInsecureLib - Use of weak crypto MD5
+	SampleCodeInsecureLib = []CodeSample{
+		{[]string{`
+package main
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+func main() {
+	f, err := os.Open("file.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%x", h.Sum(nil))
+}`}, 1}}
+
+	// SampleCodeInsecureLibb - Use of weak crypto SHA1
+	SampleCodeInsecureLibb = []CodeSample{
+		{[]string{`
+package main
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+func main() {
+	f, err := os.Open("file.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	h := sha1.New()
+	if _, err := io.Copy(h, f); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%x", h.Sum(nil))
+}`}, 1}}
+
+	// SampleCodeWeakCipherMode - hardcoded IVs, nonces and keys, and ECB mode
+	SampleCodeWeakCipherMode = []CodeSample{{[]string{`
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+)
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := []byte("1234567890123456")
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+	return ciphertext, nil
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+)
+
+func seal(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	return gcm.Seal(nil, nonce, plaintext, nil), nil
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+)
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCTR(block, iv).XORKeyStream(ciphertext, plaintext)
+	return append(iv, ciphertext...), nil
+}`}, 0}, {[]string{`
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+)
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
+	iv := ciphertext[:aes.BlockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	cipher.NewCFBEncrypter(block, iv).XORKeyStream(ciphertext[aes.BlockSize:], plaintext)
+	return ciphertext, nil
+}`}, 0}, {[]string{`
+package main
+
+import (
+	"crypto/aes"
+)
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(plaintext))
+	for i := 0; i+aes.BlockSize <= len(plaintext); i += aes.BlockSize {
+		block.Encrypt(ciphertext[i:], plaintext[i:])
+	}
+	return ciphertext, nil
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"crypto/aes"
+	"fmt"
+)
+
+func main() {
+	block, err := aes.NewCipher([]byte("thisis32bitlongpassphraseimusing"))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(block.BlockSize())
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"crypto/aes"
+	"fmt"
+)
+
+const secret = "thisis32bitlongpassphraseimusing"
+
+func main() {
+	key := []byte(secret)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(block.BlockSize())
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+)
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := []byte("1234567890123456")
+	ciphertext := make([]byte, len(plaintext))
+	if len(plaintext) > 0 {
+		iv := make([]byte, aes.BlockSize)
+		if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+			return nil, err
+		}
+		cipher.NewCTR(block, iv).XORKeyStream(ciphertext, plaintext)
+		return append(iv, ciphertext...), nil
+	}
+	return iv, nil
+}`}, 0}, {[]string{`
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+)
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(plaintext))
+	{
+		iv := []byte("1234567890123456")
+		cipher.NewCTR(block, iv).XORKeyStream(ciphertext, plaintext)
+	}
+	return append(iv, ciphertext...), nil
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+)
+
+var iv = make([]byte, aes.BlockSize)
+
+func init() {
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		panic(err)
+	}
+}
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCTR(block, iv).XORKeyStream(ciphertext, plaintext)
+	return ciphertext, nil
+}`}, 0}, {[]string{`
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+)
+
+var iv = make([]byte, aes.BlockSize)
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCTR(block, iv).XORKeyStream(ciphertext, plaintext)
+	return ciphertext, nil
+}`}, 1}}
+
+	// SampleCodeBadTls - TLS settings
+	SampleCodeBadTls = []CodeSample{{[]string{`
+// InsecureSkipVerify
+package main
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+func main() {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+
+	client := &http.Client{Transport: tr}
+	_, err := client.Get("https://golang.org/")
+	if err != nil {
+		fmt.Println(err)
+	}
+}`}, 1}, {[]string{
+		`
+// Insecure minimum version
+package main
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+func main() {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{MinVersion: 0},
+	}
+	client := &http.Client{Transport: tr}
+	_, err := client.Get("https://golang.org/")
+	if err != nil {
+		fmt.Println(err)
+	}
+}`}, 1}, {[]string{`
+// Insecure max version
+package main
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+func main() {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{MaxVersion: 0},
+	}
+	client := &http.Client{Transport: tr}
+	_, err := client.Get("https://golang.org/")
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+`}, 1}, {
+		[]string{`
+// Insecure ciphersuite selection
+package main
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+func main() {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{CipherSuites: []uint16{
+						tls.TLS_RSA_WITH_RC4_128_SHA,
+						tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+						},},
 	}
 	client := &http.Client{Transport: tr}
 	_, err := client.Get("https://golang.org/")
@@ -1002,8 +1701,138 @@ func main() {
 	}
 }`}, 1}}
 
-	// SampleCodemin-key-rsa - weak key strength
-	SampleCodemin-key-rsa = []CodeSample{
+	// SampleCodeBadTlsSSA - bad TLS settings resolved with the SSA form
+	SampleCodeBadTlsSSA = []CodeSample{{[]string{`
+// InsecureSkipVerify held in a variable
+package main
+import (
+	"crypto/tls"
+)
+func main() {
+	skip := true
+	conn, err := tls.Dial("tcp", "golang.org:443", &tls.Config{InsecureSkipVerify: skip})
+	if err == nil {
+		conn.Close()
+	}
+}`}, 1}, {[]string{`
+// Minimum version held in a struct field
+package main
+import (
+	"crypto/tls"
+)
+type options struct {
+	minVersion uint16
+}
+func main() {
+	opts := options{minVersion: 0x0300}
+	conn, err := tls.Dial("tcp", "golang.org:443", &tls.Config{MinVersion: opts.minVersion})
+	if err == nil {
+		conn.Close()
+	}
+}`}, 1}, {[]string{`
+// InsecureSkipVerify assigned a package variable
+package main
+import (
+	"crypto/tls"
+)
+var insecure = true
+func main() {
+	config := &tls.Config{}
+	config.InsecureSkipVerify = insecure
+	conn, err := tls.Dial("tcp", "golang.org:443", config)
+	if err == nil {
+		conn.Close()
+	}
+}`}, 1}, {[]string{`
+// Safe settings held in variables
+package main
+import (
+	"crypto/tls"
+)
+const minVersion = tls.VersionTLS12
+func main() {
+	skip := false
+	conn, err := tls.Dial("tcp", "golang.org:443", &tls.Config{InsecureSkipVerify: skip, MinVersion: minVersion})
+	if err == nil {
+		conn.Close()
+	}
+}`}, 0}}
+
+	// SampleCodeMinKeyRsaSSA - weak key strength resolved with the SSA form
+	SampleCodeMinKeyRsaSSA = []CodeSample{{[]string{`
+package main
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+)
+func main() {
+	bits := 512
+	pvk, err := rsa.GenerateKey(rand.Reader, bits*2)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(pvk)
+}`}, 1}, {[]string{`
+package main
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+)
+var keySize = 4096
+func main() {
+	pvk, err := rsa.GenerateKey(rand.Reader, keySize)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(pvk)
+}`}, 0}, {[]string{`
+// Key size written through a pointer
+package main
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+)
+func fill(bits *int) {
+	*bits = 4096
+}
+func main() {
+	bits := 1024
+	fill(&bits)
+	pvk, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(pvk)
+}`}, 0}, {[]string{`
+// Key size held in a field of a struct written through a pointer
+package main
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+)
+type options struct {
+	bits int
+}
+func fill(o *options) {
+	o.bits = 4096
+}
+func main() {
+	o := options{}
+	o.bits = 1024
+	fill(&o)
+	pvk, err := rsa.GenerateKey(rand.Reader, o.bits)
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(pvk)
+}`}, 0}}
+
+	// SampleCodeMinKeyRsa - weak key strength
+	SampleCodeMinKeyRsa = []CodeSample{
 		{[]string{`
 package main
 import (
@@ -1020,8 +1849,110 @@ func main() {
 	fmt.Println(pvk)
 }`}, 1}}
 
-	// SampleCodeinsecure-rand - weak random number
-	SampleCodeinsecure-rand = []CodeSample{
+	// SampleCodeWeakPasswordHash - passwords hashed with a fast hash function
+	SampleCodeWeakPasswordHash = []CodeSample{{[]string{`
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+func hashPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"crypto/md5"
+	"fmt"
+)
+
+func main() {
+	data := []byte("hunter2")
+	passwordHash := md5.Sum(data)
+	fmt.Printf("%x\n", passwordHash)
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+)
+
+func digest(pwd, salt []byte) []byte {
+	mac := hmac.New(sha512.New, salt)
+	mac.Write(pwd)
+	return mac.Sum(nil)
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+func checksum(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}`}, 0}}
+
+	// SampleCodeWeakKDF - weak parameters of key derivation functions
+	SampleCodeWeakKDF = []CodeSample{{[]string{`
+package main
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+func hash(password []byte) ([]byte, error) {
+	return bcrypt.GenerateFromPassword(password, bcrypt.MinCost)
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+func hash(password []byte) ([]byte, error) {
+	return bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
+}`}, 0}, {[]string{`
+package main
+
+import (
+	"golang.org/x/crypto/scrypt"
+)
+
+func derive(password, salt []byte) ([]byte, error) {
+	return scrypt.Key(password, salt, 1<<14, 8, 1, 32)
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"golang.org/x/crypto/argon2"
+)
+
+func derive(password, salt []byte) ([]byte, []byte) {
+	strong := argon2.IDKey(password, salt, 2, 64*1024, 4, 32)
+	weak := argon2.Key(password, salt, 3, 8*1024, 4, 32)
+	return strong, weak
+}`}, 1}, {[]string{`
+package main
+
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const iterations = 4096
+
+func derive(password, salt []byte) []byte {
+	return pbkdf2.Key(password, salt, iterations, 32, sha256.New)
+}`}, 1}}
+
+	// SampleCodeInsecureRand - weak random number
+	SampleCodeInsecureRand = []CodeSample{
 		{[]string{`
 package main
 import "crypto/rand"
@@ -1047,8 +1978,8 @@ func main() {
 	println(i)
 }`}, 0}}
 
-	// SampleCodeblacklist-md5 - Blacklisted import MD5
-	SampleCodeblacklist-md5 = []CodeSample{
+	// SampleCodeBlacklistMd5 - Blacklisted import MD5
+	SampleCodeBlacklistMd5 = []CodeSample{
 		{[]string{`
 package main
 import (
@@ -1062,8 +1993,8 @@ func main() {
 	}
 }`}, 1}}
 
-	// SampleCodeblacklist-des - Blacklisted import DES
-	SampleCodeblacklist-des = []CodeSample{
+	// SampleCodeBlacklistDes - Blacklisted import DES
+	SampleCodeBlacklistDes = []CodeSample{
 		{[]string{`
 package main
 import (
@@ -1090,8 +2021,8 @@ func main() {
 	fmt.Println("Secret message is: %s", hex.EncodeToString(ciphertext))
 }`}, 1}}
 
-	// SampleCodeblacklist-rc4 - Blacklisted import RC4
-	SampleCodeblacklist-rc4 = []CodeSample{{[]string{`
+	// SampleCodeBlacklistRc4 - Blacklisted import RC4
+	SampleCodeBlacklistRc4 = []CodeSample{{[]string{`
 package main
 import (
 	"crypto/rc4"
@@ -1109,8 +2040,8 @@ func main() {
 	fmt.Println("Secret message is: %s", hex.EncodeToString(ciphertext))
 }`}, 1}}
 
-	// SampleCodeblacklist-http-cgi - Blacklisted import CGI
-	SampleCodeblacklist-http-cgi = []CodeSample{{[]string{`
+	// SampleCodeBlacklistHttpCgi - Blacklisted import CGI
+	SampleCodeBlacklistHttpCgi = []CodeSample{{[]string{`
 package main
 import (
 	"net/http/cgi"
@@ -1119,8 +2050,8 @@ import (
 func main() {
 	cgi.Serve(http.FileServer(http.Dir("/usr/share/doc")))
 }`}, 1}}
-	// SampleCodeblacklist-sha1 - Blacklisted import SHA1
-	SampleCodeblacklist-sha1 = []CodeSample{
+	// SampleCodeBlacklistSha1 - Blacklisted import SHA1
+	SampleCodeBlacklistSha1 = []CodeSample{
 		{[]string{`
 package main
 import (